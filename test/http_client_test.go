@@ -0,0 +1,2942 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fixture 는 테스트 서버가 특정 경로에 돌려줄 응답입니다.
+type fixture struct {
+	status int           // 응답 상태 코드 (0이면 200)
+	body   string        // 응답 본문
+	delay  time.Duration // 응답 전 대기 시간 (타임아웃 테스트용)
+
+	contentType string // Content-Type 헤더 (비어 있으면 application/json)
+}
+
+// newClientTestServer 함수는 경로별 JSON fixture 문자열로 응답하는 테스트 서버와, 그 서버를 가리키는 Client 를 함께 만듭니다.
+// 모든 응답은 200 OK 와 Content-Type: application/json 으로 보내며, 등록되지 않은 경로는 404 입니다.
+// 서버는 테스트가 끝나면 자동으로 닫힙니다.
+// 이 클라이언트는 http_client.go 한 파일로 빌드하는 package main 이라 다른 패키지가 import 할 수 없으므로,
+// 내보낸 clienttest.NewServer 대신 이 파일의 테스트 전용 도우미로만 제공합니다. (같은 파일의 테스트에서만 사용)
+func newClientTestServer(t *testing.T, routes map[string]string) (*httptest.Server, *Client) {
+	t.Helper()
+	fixtures := make(map[string]fixture, len(routes))
+	for path, body := range routes {
+		fixtures[path] = fixture{body: body}
+	}
+	srv := newFixtureServer(t, fixtures)
+	return srv, newTestClient(t, srv.URL, 5*time.Second)
+}
+
+// newTestClient 함수는 NewClient 로 Client 를 만들고, 실패하면 테스트를 중단합니다.
+func newTestClient(tb testing.TB, baseURL string, timeout time.Duration) *Client {
+	tb.Helper()
+	c, err := NewClient(baseURL, timeout)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return c
+}
+
+// newFixtureServer 함수는 경로별 fixture 를 돌려주는 httptest.Server 를 시작합니다.
+// 등록되지 않은 경로는 404 로 응답합니다.
+func newFixtureServer(t *testing.T, routes map[string]fixture) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, ok := routes[r.URL.Path]
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{}`))
+			return
+		}
+		if f.delay > 0 {
+			select {
+			case <-time.After(f.delay):
+			case <-r.Context().Done(): // 클라이언트가 포기하면 바로 종료
+				return
+			}
+		}
+		status := f.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		contentType := f.contentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		w.Write([]byte(f.body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// rewriteDoer 는 모든 요청의 호스트를 테스트 서버로 바꿔 보내는 Doer 입니다.
+// FetchPost/FetchAllPosts 는 기본 URL 을 사용하므로 이 방식으로 모의 서버에 연결합니다.
+type rewriteDoer struct {
+	target *url.URL
+	client *http.Client
+}
+
+func (d *rewriteDoer) Do(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = d.target.Scheme
+	req.URL.Host = d.target.Host
+	return d.client.Do(req)
+}
+
+// newRewriteDoer 함수는 srv 로 요청을 보내는 rewriteDoer 를 생성합니다.
+func newRewriteDoer(t *testing.T, srv *httptest.Server, timeout time.Duration) *rewriteDoer {
+	t.Helper()
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("테스트 서버 URL 해석 실패: %v", err)
+	}
+	return &rewriteDoer{target: u, client: &http.Client{Timeout: timeout}}
+}
+
+// isHTTPStatus 함수는 err 가 주어진 상태 코드의 *HTTPError 인지 확인하는 검사 함수를 반환합니다.
+func isHTTPStatus(code int) func(error) bool {
+	return func(err error) bool {
+		var he *HTTPError
+		return errors.As(err, &he) && he.StatusCode == code
+	}
+}
+
+// isDecodeError 함수는 err 가 *DecodeError 인지 확인합니다.
+func isDecodeError(err error) bool {
+	var de *DecodeError
+	return errors.As(err, &de)
+}
+
+// isTimeout 함수는 err 가 타임아웃으로 인한 net.Error 인지 확인합니다.
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+const postFixture = `{"userId": 1, "id": 1, "title": "제목", "body": "내용"}`
+
+func TestFetchPost(t *testing.T) {
+	tests := []struct {
+		name    string
+		route   fixture
+		timeout time.Duration
+		want    *Post
+		wantErr func(error) bool
+	}{
+		{
+			name:  "성공",
+			route: fixture{body: postFixture},
+			want:  &Post{UserID: 1, ID: 1, Title: "제목", Body: "내용"},
+		},
+		{
+			name:    "404",
+			route:   fixture{status: http.StatusNotFound, body: `{}`},
+			wantErr: isHTTPStatus(http.StatusNotFound),
+		},
+		{
+			name:    "잘못된 JSON",
+			route:   fixture{body: `{"userId": 1, "id":`},
+			wantErr: isDecodeError,
+		},
+		{
+			name:    "타임아웃",
+			route:   fixture{body: postFixture, delay: time.Second},
+			timeout: 50 * time.Millisecond,
+			wantErr: isTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]fixture{"/posts/1": tt.route})
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = 5 * time.Second
+			}
+
+			got, err := FetchPost(newRewriteDoer(t, srv, timeout), 1)
+			if tt.wantErr != nil {
+				if err == nil || !tt.wantErr(err) {
+					t.Fatalf("예상한 종류의 오류가 아닙니다: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("예상치 못한 오류: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchAllPosts(t *testing.T) {
+	tests := []struct {
+		name    string
+		route   fixture
+		want    []Post
+		wantErr func(error) bool
+	}{
+		{
+			name:  "성공",
+			route: fixture{body: `[{"userId":1,"id":1,"title":"a","body":"x"},{"userId":2,"id":2,"title":"b","body":"y"}]`},
+			want: []Post{
+				{UserID: 1, ID: 1, Title: "a", Body: "x"},
+				{UserID: 2, ID: 2, Title: "b", Body: "y"},
+			},
+		},
+		{
+			name:  "빈 배열",
+			route: fixture{body: `[]`},
+			want:  []Post{},
+		},
+		{
+			name:    "잘못된 JSON",
+			route:   fixture{body: `[{"id": 1},`},
+			wantErr: isDecodeError,
+		},
+		{
+			name:    "서버 오류",
+			route:   fixture{status: http.StatusInternalServerError, body: `{"error":"boom"}`},
+			wantErr: isHTTPStatus(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]fixture{"/posts": tt.route})
+
+			got, err := FetchAllPosts(newRewriteDoer(t, srv, 5*time.Second))
+			if tt.wantErr != nil {
+				if err == nil || !tt.wantErr(err) {
+					t.Fatalf("예상한 종류의 오류가 아닙니다: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("예상치 못한 오류: %v", err)
+			}
+			if got == nil {
+				t.Fatal("nil 슬라이스가 반환되었습니다")
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	srv := newFixtureServer(t, map[string]fixture{
+		"/posts/1": {body: postFixture, delay: time.Second},
+	})
+
+	t.Run("클라이언트 타임아웃", func(t *testing.T) {
+		c := newTestClient(t, srv.URL, 50*time.Millisecond)
+		c.MaxRetries = 0
+		_, err := c.GetPostContext(context.Background(), 1)
+		if !errors.Is(err, ErrTimeout) || errors.Is(err, ErrCanceled) {
+			t.Errorf("ErrTimeout 이면서 ctx 마감과는 구분되어야 합니다: %v", err)
+		}
+	})
+
+	t.Run("ctx 마감", func(t *testing.T) {
+		c := newTestClient(t, srv.URL, 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := c.GetPostContext(ctx, 1)
+		if !errors.Is(err, ErrCanceled) || !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrTimeout) {
+			t.Errorf("ErrCanceled 와 context.DeadlineExceeded 여야 합니다: %v", err)
+		}
+	})
+
+	t.Run("ctx 취소", func(t *testing.T) {
+		c := newTestClient(t, srv.URL, 5*time.Second)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := c.GetPostContext(ctx, 1)
+		if !errors.Is(err, ErrCanceled) || !errors.Is(err, context.Canceled) {
+			t.Errorf("ErrCanceled 와 context.Canceled 여야 합니다: %v", err)
+		}
+	})
+
+	t.Run("연결 오류", func(t *testing.T) {
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close() // 닫힌 서버로 연결하면 연결 거부
+		c := newTestClient(t, closed.URL, 5*time.Second)
+		c.MaxRetries = 0
+		_, err := c.GetPostContext(context.Background(), 1)
+		if !errors.Is(err, ErrConnection) {
+			t.Errorf("ErrConnection 이어야 합니다: %v", err)
+		}
+	})
+}
+
+func TestGetTodos(t *testing.T) {
+	srv := newFixtureServer(t, map[string]fixture{
+		"/todos":   {body: `[{"userId":1,"id":1,"title":"a","completed":false},{"userId":1,"id":2,"title":"b","completed":true}]`},
+		"/todos/2": {body: `{"userId":1,"id":2,"title":"b","completed":true}`},
+	})
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	ctx := context.Background()
+
+	todos, err := c.GetTodos(ctx)
+	if err != nil {
+		t.Fatalf("GetTodos: %v", err)
+	}
+	want := []Todo{
+		{UserID: 1, ID: 1, Title: "a", Completed: false},
+		{UserID: 1, ID: 2, Title: "b", Completed: true},
+	}
+	if !reflect.DeepEqual(todos, want) {
+		t.Errorf("got %+v, want %+v", todos, want)
+	}
+
+	todo, err := c.GetTodo(ctx, 2)
+	if err != nil {
+		t.Fatalf("GetTodo: %v", err)
+	}
+	if !todo.Completed {
+		t.Error("completed 가 true 로 디코딩되어야 합니다")
+	}
+
+	if _, err := c.GetTodosByUser(ctx, 0); err == nil {
+		t.Error("userID 가 0이면 오류를 반환해야 합니다")
+	}
+}
+
+func TestStreamPosts(t *testing.T) {
+	srv := newFixtureServer(t, map[string]fixture{
+		"/posts": {body: `[{"userId":1,"id":1,"title":"a"},{"userId":1,"id":2,"title":"b"},{"userId":1,"id":3,"title":"c"}]`},
+	})
+	c := newTestClient(t, srv.URL, 5*time.Second)
+
+	var ids []int
+	err := c.StreamPosts(context.Background(), func(p Post) error {
+		ids = append(ids, p.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamPosts: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2, 3}) {
+		t.Errorf("ids = %v", ids)
+	}
+
+	// 콜백이 오류를 반환하면 즉시 멈추고 그 오류를 전달
+	stop := errors.New("stop")
+	var seen int
+	err = c.StreamPosts(context.Background(), func(p Post) error {
+		seen++
+		return stop
+	})
+	if !errors.Is(err, stop) || seen != 1 {
+		t.Errorf("콜백 오류에서 멈춰야 합니다: err=%v, seen=%d", err, seen)
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	srv := newFixtureServer(t, map[string]fixture{"/posts": {body: "[" + postFixture + "," + postFixture + "]"}})
+
+	_, err := newTestClient(t, srv.URL, 5*time.Second).WithMaxBodySize(int64(len(postFixture))).GetAllPostsContext(context.Background())
+	if !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("err = %v, ErrBodyTooLarge 를 기대함", err)
+	}
+
+	posts, err := newTestClient(t, srv.URL, 5*time.Second).GetAllPostsContext(context.Background())
+	if err != nil || len(posts) != 2 {
+		t.Fatalf("기본 한도에서는 성공해야 합니다: len=%d, err=%v", len(posts), err)
+	}
+
+	// 한도를 넘은 뒤에도 계속 읽으면 음수가 아닌 0, ErrBodyTooLarge 를 반환
+	b := newLimitedBody(io.NopCloser(strings.NewReader(strings.Repeat("x", 20))), 10)
+	buf := make([]byte, 4)
+	var got []int
+	for i := 0; i < 5; i++ {
+		n, err := b.Read(buf)
+		got = append(got, n)
+		if i >= 2 && !errors.Is(err, ErrBodyTooLarge) {
+			t.Errorf("Read #%d: err = %v; want ErrBodyTooLarge", i+1, err)
+		}
+	}
+	if !reflect.DeepEqual(got, []int{4, 4, 2, 0, 0}) {
+		t.Errorf("Read 바이트 수 = %v; want [4 4 2 0 0]", got)
+	}
+
+	data, err := io.ReadAll(newLimitedBody(io.NopCloser(strings.NewReader(strings.Repeat("x", 20))), 10))
+	if len(data) != 10 || !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("io.ReadAll = %d 바이트, %v; want 10 바이트, ErrBodyTooLarge", len(data), err)
+	}
+}
+
+func TestSortPosts(t *testing.T) {
+	posts := []Post{
+		{ID: 1, UserID: 2, Title: "b"},
+		{ID: 2, UserID: 1, Title: "a"},
+		{ID: 3, UserID: 2, Title: "c"},
+	}
+	ids := func() []int {
+		out := make([]int, len(posts))
+		for i, p := range posts {
+			out[i] = p.ID
+		}
+		return out
+	}
+
+	if err := SortPosts(posts, "title", false); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(); !reflect.DeepEqual(got, []int{2, 1, 3}) {
+		t.Errorf("title 오름차순 = %v", got)
+	}
+	// userId 가 같은 1, 3 은 정렬 전 순서를 유지해야 함
+	if err := SortPosts(posts, "userId", true); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(); !reflect.DeepEqual(got, []int{1, 3, 2}) {
+		t.Errorf("userId 내림차순 = %v", got)
+	}
+	if err := SortPosts(posts, "body", false); err == nil {
+		t.Error("알 수 없는 정렬 기준은 오류여야 합니다")
+	}
+}
+
+func TestStrictDecoding(t *testing.T) {
+	srv := newFixtureServer(t, map[string]fixture{
+		"/posts/1": {body: `{"userId":1,"id":1,"title":"t","body":"b","tags":["x"]}`},
+	})
+
+	// 기본값(관대한 모드)에서는 모르는 필드를 무시
+	if _, err := newTestClient(t, srv.URL, 5*time.Second).GetPost(1); err != nil {
+		t.Fatalf("기본 모드에서는 성공해야 합니다: %v", err)
+	}
+
+	_, err := newTestClient(t, srv.URL, 5*time.Second).WithStrictDecoding(true).GetPost(1)
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		t.Fatalf("err = %v, *DecodeError 를 기대함", err)
+	}
+	if de.Field != "tags" {
+		t.Errorf("Field = %q, want %q", de.Field, "tags")
+	}
+}
+
+func TestContentTypeCheck(t *testing.T) {
+	srv := newFixtureServer(t, map[string]fixture{
+		"/posts/1": {body: `<html>점검 중</html>`, contentType: "text/html; charset=utf-8"},
+		"/posts/2": {body: postFixture, contentType: "application/json; charset=utf-8"},
+		"/posts/3": {body: postFixture, contentType: "application/vnd.api+json"},
+	})
+	c := newTestClient(t, srv.URL, 5*time.Second)
+
+	if _, err := c.GetPost(1); !errors.Is(err, ErrNotJSON) {
+		t.Errorf("HTML 응답: err = %v, ErrNotJSON 을 기대함", err)
+	}
+	for _, id := range []int{2, 3} {
+		if _, err := c.GetPost(id); err != nil {
+			t.Errorf("게시물 %d: JSON 타입은 허용되어야 합니다: %v", id, err)
+		}
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	srv := newFixtureServer(t, map[string]fixture{
+		"/posts/1": {status: http.StatusInternalServerError, body: `{}`},
+		"/posts/2": {body: postFixture},
+	})
+	c := newTestClient(t, srv.URL, 5*time.Second).WithCircuitBreaker(2, 50*time.Millisecond)
+	c.MaxRetries = 0
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetPost(1); !isHTTPStatus(http.StatusInternalServerError)(err) {
+			t.Fatalf("%d번째 요청: err = %v, 500 을 기대함", i+1, err)
+		}
+	}
+	// 연속 2번 실패했으므로 정상 경로도 즉시 차단
+	if _, err := c.GetPost(2); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, ErrCircuitOpen 을 기대함", err)
+	}
+
+	// cooldown 이 지나면 시험 요청이 성공해 회로가 닫힘
+	time.Sleep(60 * time.Millisecond)
+	if _, err := c.GetPost(2); err != nil {
+		t.Fatalf("half-open 시험 요청은 성공해야 합니다: %v", err)
+	}
+	if _, err := c.GetPost(2); err != nil {
+		t.Fatalf("회로가 닫힌 뒤에는 성공해야 합니다: %v", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(postFixture))
+	}))
+	defer srv.Close()
+
+	if _, err := newTestClient(t, srv.URL, 5*time.Second).GetPost(1); err != nil {
+		t.Fatalf("429 뒤 재시도는 성공해야 합니다: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("요청 횟수 = %d, want 2", calls)
+	}
+
+	now := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+	for value, want := range map[string]time.Duration{
+		"120":                           2 * time.Minute,
+		"Wed, 21 Oct 2015 07:28:30 GMT": 30 * time.Second,
+		"Wed, 21 Oct 2015 07:00:00 GMT": 0, // 이미 지난 날짜
+	} {
+		if got, ok := parseRetryAfter(value, now); !ok || got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v", value, got, ok, want)
+		}
+	}
+	if _, ok := parseRetryAfter("soon", now); ok {
+		t.Error("해석할 수 없는 값은 ok=false 여야 합니다")
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	srv := newFixtureServer(t, map[string]fixture{"/posts/1": {body: postFixture}})
+	m := &InMemoryMetrics{}
+	c := newTestClient(t, srv.URL, 5*time.Second).WithMetrics(m)
+
+	c.GetPost(1)
+	c.GetPost(2) // 등록되지 않은 경로는 404
+	c.GetPost(1)
+
+	want := map[int]int{http.StatusOK: 2, http.StatusNotFound: 1}
+	if got := m.Counts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Counts() = %v, want %v", got, want)
+	}
+}
+
+func TestFallbackURLs(t *testing.T) {
+	primary := newFixtureServer(t, map[string]fixture{"/posts/1": {status: http.StatusBadGateway, body: `{}`}})
+	fallback := newFixtureServer(t, map[string]fixture{"/posts/1": {body: postFixture}})
+
+	c := newTestClient(t, primary.URL, 5*time.Second).WithFallbackURLs("http://127.0.0.1:1", fallback.URL+"/")
+	c.MaxRetries = 0
+	post, err := c.GetPost(1)
+	if err != nil {
+		t.Fatalf("대체 URL 로 성공해야 합니다: %v", err)
+	}
+	if post.ID != 1 {
+		t.Errorf("post.ID = %d, want 1", post.ID)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("X-Request-ID"))
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL, 5*time.Second)
+
+	// 호출한 쪽이 정한 ID 는 그대로 전달되고 오류에서도 읽을 수 있어야 함
+	_, err := c.GetPostContext(WithRequestID(context.Background(), "trace-123"), 1)
+	var he *HTTPError
+	if !errors.As(err, &he) || he.RequestID != "trace-123" {
+		t.Fatalf("err = %v, RequestID trace-123 을 기대함", err)
+	}
+
+	// 지정하지 않으면 요청마다 새 ID 를 생성
+	c.GetPost(1)
+	c.GetPost(1)
+	if len(seen) != 3 || seen[0] != "trace-123" || seen[1] == "" || seen[1] == seen[2] {
+		t.Errorf("전달된 요청 ID = %q", seen)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name string
+		b    Backoff
+		want []time.Duration // attempt 1, 2, 3, ... 의 대기 시간
+	}{
+		{"constant", ConstantBackoff{Delay: 50 * ms}, []time.Duration{50 * ms, 50 * ms, 50 * ms}},
+		{"exponential", ExponentialBackoff{Base: 100 * ms}, []time.Duration{100 * ms, 200 * ms, 400 * ms, 800 * ms}},
+		{"exponential max", ExponentialBackoff{Base: 100 * ms, Max: 300 * ms}, []time.Duration{100 * ms, 200 * ms, 300 * ms, 300 * ms}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				if got := tt.b.Next(i + 1); got != want {
+					t.Errorf("Next(%d) = %v, want %v", i+1, got, want)
+				}
+			}
+		})
+	}
+
+	// 시도 횟수가 커도 음수나 0 으로 넘치지 않음
+	t.Run("large attempts", func(t *testing.T) {
+		for _, attempt := range []int{40, 63, 64, 100, 1000} {
+			if got := (ExponentialBackoff{Base: 100 * ms}).Next(attempt); got != math.MaxInt64 {
+				t.Errorf("Max 없음: Next(%d) = %v, want 최댓값 %v", attempt, got, time.Duration(math.MaxInt64))
+			}
+			if got := (ExponentialBackoff{Base: 100 * ms, Max: time.Minute}).Next(attempt); got != time.Minute {
+				t.Errorf("Max 1분: Next(%d) = %v, want 1m0s", attempt, got)
+			}
+			if got := defaultBackoff().Next(attempt); got < maxBackoff/2 || got > maxBackoff {
+				t.Errorf("defaultBackoff: Next(%d) = %v, [%v, %v] 범위여야 합니다", attempt, got, maxBackoff/2, maxBackoff)
+			}
+		}
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		b := ExponentialBackoff{Base: 100 * ms, Jitter: 0.5}
+		for attempt := 1; attempt <= 4; attempt++ {
+			full := ExponentialBackoff{Base: 100 * ms}.Next(attempt)
+			for i := 0; i < 100; i++ {
+				if got := b.Next(attempt); got < full/2 || got > full {
+					t.Fatalf("Next(%d) = %v, [%v, %v] 범위여야 합니다", attempt, got, full/2, full)
+				}
+			}
+		}
+	})
+
+	t.Run("client", func(t *testing.T) {
+		srv := newFixtureServer(t, map[string]fixture{"/posts/1": {status: http.StatusServiceUnavailable, body: `{}`}})
+		m := &InMemoryMetrics{}
+		c := newTestClient(t, srv.URL, 5*time.Second).WithBackoff(ConstantBackoff{}).WithMetrics(m)
+		c.GetPost(1)
+		if got := m.Counts()[http.StatusServiceUnavailable]; got != defaultMaxRetries+1 {
+			t.Errorf("시도 횟수 = %d, want %d", got, defaultMaxRetries+1)
+		}
+	})
+}
+
+func TestHTTP2(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(postFixture))
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	for _, tt := range []struct {
+		enabled bool
+		major   int
+	}{{true, 2}, {false, 1}} {
+		c := newTestClient(t, srv.URL, 5*time.Second).WithRootCAs(pool).WithHTTP2(tt.enabled)
+		resp, err := c.GetPostRaw(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("WithHTTP2(%v): %v", tt.enabled, err)
+		}
+		resp.Body.Close()
+		if resp.ProtoMajor != tt.major {
+			t.Errorf("WithHTTP2(%v): Proto = %s, want HTTP/%d", tt.enabled, resp.Proto, tt.major)
+		}
+	}
+}
+
+func TestPostFlexibleIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    Post
+		wantErr bool
+	}{
+		{"숫자", `{"userId": 2, "id": 7, "title": "t"}`, Post{UserID: 2, ID: 7, Title: "t"}, false},
+		{"문자열", `{"userId": "2", "id": "7", "title": "t"}`, Post{UserID: 2, ID: 7, Title: "t"}, false},
+		{"숫자가 아닌 문자열", `{"userId": 2, "id": "seven"}`, Post{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Post
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostUpdateOmitsUnset(t *testing.T) {
+	title := "새 제목"
+	data, err := json.Marshal(PostUpdate{Title: &title})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"title":"새 제목"}`; got != want {
+		t.Errorf("Marshal = %s, want %s", got, want)
+	}
+}
+
+func TestGetPostsStream(t *testing.T) {
+	srv := newFixtureServer(t, map[string]fixture{
+		"/posts/1": {body: `{"userId": 1, "id": 1, "title": "a"}`},
+		"/posts/2": {body: `{"userId": 1, "id": 2, "title": "b"}`},
+	})
+	c := newTestClient(t, srv.URL, 5*time.Second)
+
+	ids := make(chan int)
+	go func() {
+		defer close(ids)
+		for _, id := range []int{1, 2, 3} {
+			ids <- id
+		}
+	}()
+
+	got := map[int]error{}
+	for res := range c.GetPostsStream(context.Background(), ids, 2) {
+		if res.Err == nil && res.Post.ID != res.ID {
+			t.Errorf("결과 %d 의 Post.ID = %d", res.ID, res.Post.ID)
+		}
+		got[res.ID] = res.Err
+	}
+	if len(got) != 3 || got[1] != nil || got[2] != nil || !isHTTPStatus(http.StatusNotFound)(got[3]) {
+		t.Errorf("결과 = %v", got)
+	}
+}
+
+func TestEmptyResponse(t *testing.T) {
+	srv := newFixtureServer(t, map[string]fixture{"/posts/1": {body: ""}, "/posts": {body: "  "}})
+	c := newTestClient(t, srv.URL, 5*time.Second)
+
+	if _, err := c.GetPost(1); !errors.Is(err, ErrEmptyResponse) {
+		t.Errorf("GetPost: err = %v, ErrEmptyResponse 를 기대함", err)
+	}
+	if err := c.StreamPosts(context.Background(), func(Post) error { return nil }); !errors.Is(err, ErrEmptyResponse) {
+		t.Errorf("StreamPosts: err = %v, ErrEmptyResponse 를 기대함", err)
+	}
+}
+
+func TestHostRegistry(t *testing.T) {
+	RegisterHost("https://api.example.com/", WithDefaultHeader("X-Api-Key", "secret"), WithTimeout(time.Second))
+	t.Cleanup(func() { RegisterHost("https://api.example.com") })
+
+	c, err := ClientFor("https://api.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.headers.Get("X-Api-Key"); got != "secret" {
+		t.Errorf("X-Api-Key = %q, want secret", got)
+	}
+	if got := c.httpClient().Timeout; got != time.Second {
+		t.Errorf("Timeout = %v, want 1s", got)
+	}
+
+	// 등록되지 않은 호스트는 기본 Client
+	other, err := ClientFor("https://other.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(other.headers) != 0 || other.httpClient().Timeout != defaultTimeout {
+		t.Errorf("기본 Client 여야 합니다: headers=%v, timeout=%v", other.headers, other.httpClient().Timeout)
+	}
+}
+
+func TestGetPostsModes(t *testing.T) {
+	srv := newFixtureServer(t, map[string]fixture{
+		"/posts/1": {body: `{"userId": 1, "id": 1, "title": "a"}`},
+		"/posts/3": {body: `{"userId": 1, "id": 3, "title": "c"}`},
+	})
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	ids := []int{1, 2, 3}
+
+	if posts, err := c.GetPosts(context.Background(), ids, 2, true); err == nil || posts != nil {
+		t.Errorf("fail-fast: posts=%v, err=%v; 오류만 반환해야 합니다", posts, err)
+	}
+
+	posts, err := c.GetPosts(context.Background(), ids, 2, false)
+	if !isHTTPStatus(http.StatusNotFound)(err) {
+		t.Fatalf("best-effort: err = %v, 404 를 포함해야 합니다", err)
+	}
+	if len(posts) != 3 || posts[0].ID != 1 || posts[1].ID != 0 || posts[2].ID != 3 {
+		t.Errorf("best-effort: posts = %v", posts)
+	}
+}
+
+func TestStreamPostsChunked(t *testing.T) {
+	firstSeen := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json") // Content-Length 없이 chunked 로 전송
+		flusher := w.(http.Flusher)
+		w.Write([]byte(`[{"userId":1,"id":1,"title":"a"}`))
+		flusher.Flush()
+
+		// 클라이언트가 첫 원소를 처리해야만 나머지를 보냄: 전체 본문을 버퍼링하면 여기서 멈춤
+		select {
+		case <-firstSeen:
+		case <-time.After(2 * time.Second):
+			return
+		}
+		for id := 2; id <= 3; id++ {
+			fmt.Fprintf(w, `,{"userId":1,"id":%d,"title":"t"}`, id)
+			flusher.Flush()
+		}
+		w.Write([]byte(`]`))
+	}))
+	defer srv.Close()
+
+	var ids []int
+	err := newTestClient(t, srv.URL, 5*time.Second).StreamPosts(context.Background(), func(p Post) error {
+		if p.ID == 1 {
+			close(firstSeen)
+		}
+		ids = append(ids, p.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamPosts: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2, 3}) {
+		t.Errorf("ids = %v", ids)
+	}
+}
+
+func TestNumberToInt(t *testing.T) {
+	for in, want := range map[json.Number]int{"7": 7, "2.0": 2, "1e3": 1000} {
+		if got, err := NumberToInt(in); err != nil || got != want {
+			t.Errorf("NumberToInt(%s) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []json.Number{"1.5", "abc", "1e30"} {
+		if _, err := NumberToInt(in); err == nil {
+			t.Errorf("NumberToInt(%s) 는 오류여야 합니다", in)
+		}
+	}
+
+	_, c := newClientTestServer(t, map[string]string{"/posts/1": `{"id": 9007199254740993}`})
+	m, err := getJSON[map[string]any](context.Background(), c.WithJSONNumber(true), "/posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, ok := m["id"].(json.Number); !ok || n.String() != "9007199254740993" {
+		t.Errorf("id = %#v, 정밀도 손실 없는 json.Number 여야 합니다", m["id"])
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"userId": 1, "id": 1, "title": "캐시", "body": "본문"}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		// 실행마다 새 클라이언트를 만들어도 디스크 캐시는 유지되어야 함
+		post, err := newTestClient(t, srv.URL, 5*time.Second).WithDiskCache(dir, time.Minute).GetPost(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.Title != "캐시" {
+			t.Errorf("Title = %q", post.Title)
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("서버 호출 %d 번, 두 번째는 디스크에서 읽어야 합니다", got)
+	}
+
+	// 만료된 항목은 다시 네트워크로 가져옴
+	if _, err := newTestClient(t, srv.URL, 5*time.Second).WithDiskCache(dir, time.Nanosecond).GetPost(2); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	if _, err := newTestClient(t, srv.URL, 5*time.Second).WithDiskCache(dir, time.Nanosecond).GetPost(2); err != nil {
+		t.Fatal(err)
+	}
+	if got := hits.Load(); got != 3 {
+		t.Errorf("서버 호출 %d 번, 만료 후에는 다시 요청해야 합니다", got)
+	}
+}
+
+func TestDecodePostsFlexible(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantIDs []int
+		wantErr bool
+	}{
+		{"단건", ` {"userId": 1, "id": 3, "title": "a"}`, []int{3}, false},
+		{"배열", "\n\t[{\"id\": 1}, {\"id\": 2}]", []int{1, 2}, false},
+		{"빈 배열", `[]`, []int{}, false},
+		{"빈 입력", "  ", nil, true},
+		{"스칼라", `42`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts, err := DecodePostsFlexible(strings.NewReader(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			ids := []int{}
+			for _, p := range posts {
+				ids = append(ids, p.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+	if _, err := DecodePostsFlexible(strings.NewReader("")); !errors.Is(err, ErrEmptyResponse) {
+		t.Errorf("빈 입력 err = %v, want ErrEmptyResponse", err)
+	}
+}
+
+func TestPerAttemptTimeout(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// 첫 시도만 느리게 응답해 시도 제한 시간을 넘김
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"userId": 1, "id": 1, "title": "느림"}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, 5*time.Second).WithPerAttemptTimeout(50 * time.Millisecond).WithBackoff(ConstantBackoff{})
+	c.MaxRetries = 1
+	post, err := c.GetPost(1)
+	if err != nil {
+		t.Fatalf("시도 제한 시간 초과는 재시도되어야 합니다: %v", err)
+	}
+	if post.Title != "느림" || calls.Load() != 2 {
+		t.Errorf("post = %+v, calls = %d", post, calls.Load())
+	}
+
+	// 재시도를 모두 쓰면 ErrTimeout
+	calls.Store(0)
+	c.MaxRetries = 0
+	if _, err := c.GetPost(1); !errors.Is(err, ErrTimeout) {
+		t.Errorf("err = %v, want ErrTimeout", err)
+	}
+}
+
+func TestGzipRequest(t *testing.T) {
+	var gotEncoding string
+	var gotTitle string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEncoding = r.Header.Get("Content-Encoding")
+		var body io.Reader = r.Body
+		if gotEncoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("gzip 본문 읽기 실패: %v", err)
+				return
+			}
+			body = zr
+		}
+		var p Post
+		if err := json.NewDecoder(body).Decode(&p); err != nil {
+			t.Errorf("본문 디코딩 실패: %v", err)
+		}
+		gotTitle = p.Title
+		p.ID = 101
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(p)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, 5*time.Second).WithGzipRequest(true)
+	tests := []struct {
+		name     string
+		title    string
+		encoding string
+	}{
+		{"작은 본문은 그대로", "짧음", ""},
+		{"큰 본문은 압축", strings.Repeat("가", 1000), "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := c.CreatePost(context.Background(), Post{UserID: 1, Title: tt.title}); err != nil {
+				t.Fatal(err)
+			}
+			if gotEncoding != tt.encoding || gotTitle != tt.title {
+				t.Errorf("Content-Encoding = %q, want %q (제목 일치: %v)", gotEncoding, tt.encoding, gotTitle == tt.title)
+			}
+		})
+	}
+}
+
+func TestGetResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Total-Count", "100")
+		w.Write([]byte(`[{"userId": 1, "id": 1, "title": "a"}]`))
+	}))
+	defer srv.Close()
+
+	r, err := Get[[]Post](context.Background(), newTestClient(t, srv.URL, 5*time.Second), "/posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.StatusCode != http.StatusOK || r.Header.Get("X-Total-Count") != "100" || r.Elapsed <= 0 {
+		t.Errorf("Response = %+v", r)
+	}
+	if len(r.Value) != 1 || r.Value[0].Title != "a" {
+		t.Errorf("Value = %+v", r.Value)
+	}
+}
+
+func TestNewClientValidatesBaseURL(t *testing.T) {
+	for _, u := range []string{"api.example.com", "ftp://example.com", "https://", "http://[::1"} {
+		if _, err := NewClient(u, time.Second); err == nil {
+			t.Errorf("NewClient(%q) 는 오류여야 합니다", u)
+		}
+	}
+	if _, err := NewClient("https://jsonplaceholder.typicode.com/", time.Second); err != nil {
+		t.Errorf("올바른 URL 이 거부되었습니다: %v", err)
+	}
+	if _, err := ClientFor("example.com"); err == nil {
+		t.Error("ClientFor 도 잘못된 URL 을 거부해야 합니다")
+	}
+}
+
+func TestParseLinkHeader(t *testing.T) {
+	tests := []struct {
+		in   string
+		want map[string]string
+	}{
+		{``, map[string]string{}},
+		{`<https://api.example.com/posts?_page=2>; rel="next"`, map[string]string{"next": "https://api.example.com/posts?_page=2"}},
+		{
+			`<https://api.example.com/posts?a=1,2>; rel="next"; title="x, y; z", </posts?_page=9>; REL="last first"`,
+			map[string]string{"next": "https://api.example.com/posts?a=1,2", "last": "/posts?_page=9", "first": "/posts?_page=9"},
+		},
+		{`<p2>; rel=next, garbage, <p3>; rel="next"`, map[string]string{"next": "p2"}},
+	}
+	for _, tt := range tests {
+		if got := parseLinkHeader(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseLinkHeader(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGetPostsFollowNext(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("_page"))
+		w.Header().Set("Content-Type", "application/json")
+		switch page {
+		case 1: // 절대 링크
+			w.Header().Set("Link", `<`+srv.URL+`/posts?_page=2&_limit=1>; rel="next"`)
+		case 2: // 상대 링크와 다른 rel 이 섞인 헤더
+			w.Header().Set("Link", `</posts?_page=1&_limit=1>; rel="first", </posts?_page=3&_limit=1>; rel="next"`)
+		case 4:
+			w.Header().Set("Link", `<https://evil.example.com/posts?_page=5>; rel="next"`)
+		}
+		fmt.Fprintf(w, `[{"userId": 1, "id": %d, "title": "p"}]`, page)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	posts, err := c.GetPostsFollowNext(context.Background(), PostQuery{Page: 1, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, p := range posts {
+		ids = append(ids, p.ID)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2, 3}) {
+		t.Errorf("ids = %v, want [1 2 3]", ids)
+	}
+
+	if _, err := c.GetPostsFollowNext(context.Background(), PostQuery{Page: 4, Limit: 1}); err == nil {
+		t.Error("기본 URL 밖을 가리키는 next 링크는 거부해야 합니다")
+	}
+}
+
+func TestExtractFieldPath(t *testing.T) {
+	users := `[
+		{"id": 1, "address": {"geo": {"lat": "-37.3159"}}, "tags": ["a", "b"], "score": 12345678901234567890},
+		{"id": 2, "address": {"geo": {"lat": "-43.9509"}}, "tags": ["c"], "score": 1.50}
+	]`
+	tests := []struct {
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{"id", []string{"1", "2"}, false},
+		{"address.geo.lat", []string{"-37.3159", "-43.9509"}, false},
+		{"address.geo", []string{`{"lat":"-37.3159"}`, `{"lat":"-43.9509"}`}, false},
+		{"tags.0", []string{"a", "c"}, false},
+		{"score", []string{"12345678901234567890", "1.50"}, false},
+		{"tags.1", nil, true},      // 두 번째 사용자는 태그가 하나뿐
+		{"address.zip", nil, true}, // 없는 필드
+		{"id.value", nil, true},    // 숫자 아래로는 내려갈 수 없음
+		{"address.geo.lat.x", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := extractField([]byte(users), tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("extractField(%q) err = %v, wantErr %v", tt.path, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("extractField(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestClientTestServer(t *testing.T) {
+	srv, c := newClientTestServer(t, map[string]string{
+		"/posts/1": `{"userId": 1, "id": 1, "title": "fixture"}`,
+		"/todos":   `[]`,
+	})
+	if !strings.HasPrefix(c.baseURL, srv.URL) {
+		t.Errorf("baseURL = %q, want %q", c.baseURL, srv.URL)
+	}
+	r, err := Get[Post](context.Background(), c, "/posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Value.Title != "fixture" || r.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Response = %+v", r)
+	}
+	if _, err := c.GetPost(2); err == nil {
+		t.Error("등록되지 않은 경로는 404 오류여야 합니다")
+	}
+}
+
+func TestRetryOnlyIdempotent(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		optIn  bool
+		want   int32
+	}{
+		{"GET 는 재시도", http.MethodGet, false, 3},
+		{"PUT 는 재시도", http.MethodPut, false, 3},
+		{"POST 는 한 번만", http.MethodPost, false, 1},
+		{"PATCH 는 한 번만", http.MethodPatch, false, 1},
+		{"허용하면 POST 도 재시도", http.MethodPost, true, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			c := newTestClient(t, srv.URL, 5*time.Second).WithBackoff(ConstantBackoff{}).WithRetryNonIdempotent(tt.optIn)
+			c.MaxRetries = 2
+			req, err := c.newJSONRequest(context.Background(), tt.method, "/posts", []byte(`{}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := c.send(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if got := calls.Load(); got != tt.want {
+				t.Errorf("시도 횟수 = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestOptions(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"userId": 1, "id": 1, "title": "t"}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	c.SetHeader("X-Trace", "client")
+	if _, err := c.GetPostContext(context.Background(), 1, WithRequestHeader("X-Trace", "abc"), WithRequestHeader("Accept", "application/vnd.api+json")); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("X-Trace") != "abc" || got.Get("Accept") != "application/vnd.api+json" {
+		t.Errorf("호출별 헤더가 기본값을 덮어써야 합니다: X-Trace=%q, Accept=%q", got.Get("X-Trace"), got.Get("Accept"))
+	}
+
+	// 호출별 설정은 클라이언트에 남지 않음
+	if _, err := c.GetPost(1); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("X-Trace") != "client" || got.Get("Accept") != "application/json" {
+		t.Errorf("다음 요청 헤더: X-Trace=%q, Accept=%q", got.Get("X-Trace"), got.Get("Accept"))
+	}
+}
+
+func TestTruncatedResponse(t *testing.T) {
+	const partial = `[{"userId": 1, "id": 1, "title": "a"}, {"userId": 1, "id"`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/bad" {
+			w.Write([]byte(`[{"id": 1,}]`)) // 완전히 받았지만 구문이 틀린 본문
+			return
+		}
+		// Content-Length 보다 적게 보내고 연결을 끊어 중간에 잘린 응답을 흉내 냄
+		w.Header().Set("Content-Length", "1000")
+		w.Write([]byte(partial))
+		w.(http.Flusher).Flush()
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			t.Error("Hijacker 를 지원하지 않는 서버입니다")
+			return
+		}
+		conn, _, err := hj.Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	c.MaxRetries = 0
+	_, err := c.GetAllPostsContext(context.Background())
+	if !errors.Is(err, ErrTruncatedResponse) {
+		t.Fatalf("err = %v, want ErrTruncatedResponse", err)
+	}
+	if !strings.Contains(err.Error(), fmt.Sprintf("%d 바이트", len(partial))) {
+		t.Errorf("수신한 바이트 수가 오류에 있어야 합니다: %v", err)
+	}
+	if err := c.StreamPosts(context.Background(), func(Post) error { return nil }); !errors.Is(err, ErrTruncatedResponse) {
+		t.Errorf("StreamPosts err = %v, want ErrTruncatedResponse", err)
+	}
+
+	_, err = getJSON[[]Post](context.Background(), c, "/bad")
+	var syntaxErr *json.SyntaxError
+	if errors.Is(err, ErrTruncatedResponse) || !errors.As(err, &syntaxErr) {
+		t.Errorf("구문 오류는 잘림과 구분되어야 합니다: %v", err)
+	}
+}
+
+func TestHeadPost(t *testing.T) {
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if r.URL.Path != "/posts/1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+		w.Header().Set("Content-Encoding", "gzip") // 본문이 없으므로 해제를 시도하면 안 됨
+		w.Header().Set("Content-Length", "83")
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	header, status, err := c.HeadPost(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != http.StatusOK || header.Get("Last-Modified") == "" || header.Get("Content-Length") != "83" {
+		t.Errorf("status = %d, header = %v", status, header)
+	}
+	if _, status, err := c.HeadPost(context.Background(), 2); err != nil || status != http.StatusNotFound {
+		t.Errorf("없는 게시물: status = %d, err = %v; want 404, nil", status, err)
+	}
+	if !reflect.DeepEqual(methods, []string{http.MethodHead, http.MethodHead}) {
+		t.Errorf("methods = %v", methods)
+	}
+}
+
+func TestTimeLayout(t *testing.T) {
+	_, c := newClientTestServer(t, map[string]string{
+		"/posts/1": `{"userId": 1, "id": 1, "title": "a", "createdAt": "2024-03-01T09:30:00+09:00"}`,
+		"/posts/2": `{"userId": 1, "id": 2, "title": "b", "createdAt": "2024-03-01 09:30:00"}`,
+		"/posts":   `[{"userId": 1, "id": 3, "title": "c", "createdAt": "2024-03-01 09:30:00"}, {"userId": 1, "id": 4, "title": "d"}]`,
+	})
+	want := time.Date(2024, 3, 1, 9, 30, 0, 0, time.FixedZone("", 9*60*60))
+
+	post, err := c.WithStrictDecoding(true).GetPost(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !post.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %v, want %v", post.CreatedAt, want)
+	}
+
+	// 기본 RFC3339 레이아웃과 맞지 않으면 필드 이름을 담은 오류
+	_, err = c.GetPost(2)
+	var decErr *DecodeError
+	if !errors.As(err, &decErr) || !strings.Contains(err.Error(), "createdAt") {
+		t.Errorf("err = %v, createdAt 필드 이름이 담긴 DecodeError 를 기대함", err)
+	}
+
+	c.WithTimeLayout("2006-01-02 15:04:05")
+	if post, err = c.GetPost(2); err != nil {
+		t.Fatal(err)
+	}
+	if got := post.CreatedAt.Format(time.DateTime); got != "2024-03-01 09:30:00" {
+		t.Errorf("CreatedAt = %s", got)
+	}
+	posts, err := c.GetAllPostsContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posts[0].CreatedAt.IsZero() || !posts[1].CreatedAt.IsZero() {
+		t.Errorf("목록의 CreatedAt = %v, %v", posts[0].CreatedAt, posts[1].CreatedAt)
+	}
+
+	var streamed []Post
+	if err := c.StreamPosts(context.Background(), func(p Post) error {
+		streamed = append(streamed, p)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(streamed) != 2 || streamed[0].CreatedAt.Format(time.DateTime) != "2024-03-01 09:30:00" {
+		t.Errorf("스트림의 CreatedAt = %v", streamed)
+	}
+
+	// Client 밖의 json.Unmarshal 과 DecodePostsFlexible 도 RFC3339 로 해석
+	var p Post
+	if err := json.Unmarshal([]byte(`{"id": 1, "createdAt": "2024-01-02T03:04:05Z"}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC); !p.CreatedAt.Equal(want) {
+		t.Errorf("json.Unmarshal CreatedAt = %v, want %v", p.CreatedAt, want)
+	}
+	if data, _ := json.Marshal(p); !strings.Contains(string(data), `"createdAt":"2024-01-02T03:04:05Z"`) {
+		t.Errorf("다시 직렬화하면 createdAt 이 빠짐: %s", data)
+	}
+	if err := json.Unmarshal([]byte(`{"id": 1, "createdAt": "garbage"}`), &p); err == nil || !strings.Contains(err.Error(), "createdAt") {
+		t.Errorf("json.Unmarshal(garbage): err = %v; want createdAt 필드 이름이 담긴 오류", err)
+	}
+	flex, err := DecodePostsFlexible(strings.NewReader(`{"id": 1, "createdAt": "2024-01-02T03:04:05Z"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flex[0].CreatedAt.IsZero() {
+		t.Error("DecodePostsFlexible 의 CreatedAt 이 비어 있습니다")
+	}
+	if _, err := DecodePostsFlexible(strings.NewReader(`[{"id": 1, "createdAt": "garbage"}]`)); err == nil || !strings.Contains(err.Error(), "createdAt") {
+		t.Errorf("DecodePostsFlexible(garbage): err = %v", err)
+	}
+
+	// 설정하지 않은 CreatedAt 은 요청 본문에 나가지 않음
+	data, _ := json.Marshal(Post{UserID: 1, Title: "x"})
+	if strings.Contains(string(data), "createdAt") {
+		t.Errorf("빈 CreatedAt 이 직렬화되었습니다: %s", data)
+	}
+}
+
+func TestClose(t *testing.T) {
+	var states sync.Map // 연결별 마지막 상태
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"userId": 1, "id": 1, "title": "a"}`))
+	}))
+	srv.Config.ConnState = func(conn net.Conn, state http.ConnState) { states.Store(conn, state) }
+	srv.Start()
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	if _, err := c.GetPost(1); err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+
+	// 클라이언트가 유휴 연결을 닫으면 서버 쪽 연결도 곧 닫힘
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		open := 0
+		states.Range(func(_, v any) bool {
+			if v.(http.ConnState) != http.StateClosed {
+				open++
+			}
+			return true
+		})
+		if open == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Close 후에도 연결 %d 개가 열려 있습니다", open)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Close 뒤에도 다시 요청할 수 있음
+	if _, err := c.GetPost(1); err != nil {
+		t.Errorf("Close 후 요청 실패: %v", err)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	srv := newFixtureServer(t, map[string]fixture{
+		"/posts/1": {status: http.StatusNotFound, body: `{"error": "not found", "code": 404}`},
+		"/posts/2": {status: http.StatusBadRequest, body: `{"message": "invalid id"}`},
+		"/posts/3": {status: http.StatusInternalServerError, body: `<html>oops</html>`, contentType: "text/html"},
+	})
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	c.MaxRetries = 0
+
+	_, err := c.GetPost(1)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "not found" || apiErr.Code != 404 {
+		t.Fatalf("err = %v, APIError{not found, 404} 를 기대함", err)
+	}
+	if !strings.Contains(err.Error(), "서버 메시지: not found") {
+		t.Errorf("오류 메시지에 서버 메시지가 있어야 합니다: %v", err)
+	}
+	if _, err := c.GetPost(2); !errors.As(err, &apiErr) || apiErr.Message != "invalid id" {
+		t.Errorf("message 필드도 해석해야 합니다: %v", err)
+	}
+
+	// 기대한 형태가 아니면 HTTPError 에 원시 본문만 남음
+	_, err = c.GetPost(3)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.API != nil || errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, APIError 없는 HTTPError 를 기대함", err)
+	}
+	if !strings.Contains(err.Error(), "<html>oops</html>") {
+		t.Errorf("원시 본문으로 대체해야 합니다: %v", err)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	old := Version
+	Version = "1.2.3"
+	t.Cleanup(func() { Version = old })
+
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"userId": 1, "id": 1, "title": "a"}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	if _, err := c.GetPost(1); err != nil {
+		t.Fatal(err)
+	}
+	if got != "fire-prophet-client/1.2.3" || c.UserAgent() != got {
+		t.Errorf("User-Agent = %q, UserAgent() = %q", got, c.UserAgent())
+	}
+
+	c.SetHeader("User-Agent", "custom/9")
+	if _, err := c.GetPost(1); err != nil {
+		t.Fatal(err)
+	}
+	if got != "custom/9" || c.UserAgent() != got {
+		t.Errorf("덮어쓴 User-Agent = %q, UserAgent() = %q", got, c.UserAgent())
+	}
+}
+
+func TestResponseEncodings(t *testing.T) {
+	const body = `{"userId": 1, "id": 1, "title": "압축"}`
+	compress := func(encoding string) []byte {
+		var buf bytes.Buffer
+		var w io.WriteCloser
+		switch encoding {
+		case "gzip":
+			w = gzip.NewWriter(&buf)
+		case "deflate":
+			w = zlib.NewWriter(&buf)
+		case "raw-deflate":
+			w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+		default:
+			return []byte(body)
+		}
+		w.Write([]byte(body))
+		w.Close()
+		return buf.Bytes()
+	}
+
+	var accept string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept-Encoding")
+		encoding := r.URL.Query().Get("enc")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", strings.TrimPrefix(encoding, "raw-"))
+		w.Write(compress(encoding))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	for _, enc := range []string{"gzip", "deflate", "raw-deflate", "identity"} {
+		p, err := getJSON[Post](context.Background(), c, "/posts/1?enc="+enc)
+		if err != nil || p.Title != "압축" {
+			t.Errorf("%s: post = %+v, err = %v", enc, p, err)
+		}
+	}
+	if accept != "gzip, deflate" {
+		t.Errorf("Accept-Encoding = %q", accept)
+	}
+	if _, err := getJSON[Post](context.Background(), c, "/posts/1?enc=br"); !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Errorf("br: err = %v, want ErrUnsupportedEncoding", err)
+	}
+}
+
+func TestExportAll(t *testing.T) {
+	_, c := newClientTestServer(t, map[string]string{
+		"/posts":    `[{"userId": 1, "id": 1, "title": "a"}]`,
+		"/comments": `[{"postId": 1, "id": 1, "name": "c"}]`,
+		"/albums":   `[{"userId": 1, "id": 1, "title": "al"}]`,
+		"/todos":    `[]`,
+		"/users":    `[{"id": 1, "name": "u"}]`,
+		// /photos 는 등록하지 않아 404 로 실패
+	})
+	c.MaxRetries = 0
+
+	snap, err := c.ExportAll(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Posts) != 1 || len(snap.Comments) != 1 || len(snap.Albums) != 1 || len(snap.Users) != 1 || snap.Todos == nil {
+		t.Errorf("snapshot = %+v", snap)
+	}
+	if _, ok := snap.Errors["photos"]; !ok || len(snap.Errors) != 1 {
+		t.Errorf("Errors = %v, photos 실패만 기록되어야 합니다", snap.Errors)
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var top map[string]json.RawMessage
+	json.Unmarshal(data, &top)
+	for _, key := range []string{"posts", "comments", "albums", "todos", "users", "errors"} {
+		if _, ok := top[key]; !ok {
+			t.Errorf("JSON 에 %q 키가 없습니다: %s", key, data)
+		}
+	}
+	if _, ok := top["photos"]; ok {
+		t.Errorf("실패한 리소스는 빠져야 합니다: %s", data)
+	}
+}
+
+func TestWriteJSONNumbers(t *testing.T) {
+	// 서버 응답을 디코딩했다가 다시 JSON 으로 내보내도 정수 표기가 유지되어야 함
+	posts, err := DecodePostsFlexible(strings.NewReader(`[{"userId": 1000000, "id": 1000000, "title": "<b>&</b>"}, {"userId": 1, "id": "9007199254740993"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, posts, "  "); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{`"id": 1000000,`, `"userId": 1000000,`, `"id": 9007199254740993,`, `"title": "<b>&</b>"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("출력에 %s 가 없습니다:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "e+") || strings.Contains(out, `\u003c`) {
+		t.Errorf("지수 표기나 HTML 이스케이프가 있으면 안 됩니다:\n%s", out)
+	}
+}
+
+func TestDoRequest(t *testing.T) {
+	var gotBody, gotType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody, gotType = string(b), r.Header.Get("Content-Type")
+		w.Header().Set("X-Total-Count", "7")
+		switch r.Method {
+		case http.MethodPost:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, `{"userId": 1, "id": 101, "title": "t"}`)
+		case http.MethodPut:
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"userId": 1, "id": 1, "title": "t"}`) // 201 이 아닌 200 만 성공
+		case http.MethodDelete:
+			io.WriteString(w, `ignored`)
+		case http.MethodGet:
+			w.WriteHeader(http.StatusAccepted)
+			io.WriteString(w, `raw`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	ctx := context.Background()
+
+	var post Post
+	resp, err := c.doRequest(ctx, request{method: http.MethodPost, path: "/posts", body: []byte(`{"title":"t"}`), out: &post})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.ID != 101 || gotBody != `{"title":"t"}` || gotType != "application/json" {
+		t.Errorf("post = %+v, body = %q, Content-Type = %q", post, gotBody, gotType)
+	}
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("X-Total-Count") != "7" {
+		t.Errorf("응답 메타데이터: status = %d, header = %v", resp.StatusCode, resp.Header)
+	}
+
+	// 폼 본문은 지정한 Content-Type 으로 그대로 보냄
+	if _, err := c.doRequest(ctx, request{method: http.MethodPost, path: "/posts", body: []byte("title=t"), contentType: "application/x-www-form-urlencoded", out: &post}); err != nil {
+		t.Fatal(err)
+	}
+	if gotBody != "title=t" || gotType != "application/x-www-form-urlencoded" {
+		t.Errorf("폼: body = %q, Content-Type = %q", gotBody, gotType)
+	}
+
+	if _, err := c.doRequest(ctx, request{method: http.MethodPut, path: "/posts/1", body: []byte(`{}`), out: &post}); err != nil || post.ID != 1 {
+		t.Errorf("PUT: post = %+v, err = %v", post, err)
+	}
+	if _, err := c.doRequest(ctx, request{method: http.MethodDelete, path: "/posts/1"}); err != nil {
+		t.Errorf("DELETE: err = %v", err)
+	}
+
+	// 기본 기대값(200)과 다른 상태는 오류, status 로 허용하면 read 로 본문을 직접 읽음
+	if _, err := c.doRequest(ctx, request{method: http.MethodGet, path: "/x"}); err == nil {
+		t.Error("GET 202: 기본 기대값 200 인데 오류가 없음")
+	}
+	var raw []byte
+	_, err = c.doRequest(ctx, request{method: http.MethodGet, path: "/x", status: isSuccess, read: func(resp *http.Response) (err error) {
+		raw, err = io.ReadAll(resp.Body)
+		return err
+	}})
+	if err != nil || string(raw) != "raw" {
+		t.Errorf("GET 202: raw = %q, err = %v", raw, err)
+	}
+
+	var httpErr *HTTPError
+	resp, err = c.doRequest(ctx, request{method: http.MethodPatch, path: "/posts/1", out: &post})
+	if !errors.As(err, &httpErr) || resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("PATCH 404: err = %v; want *HTTPError 와 응답", err)
+	}
+	if resp, err := c.doRequest(ctx, request{method: http.MethodPatch, path: "/posts/1", status: anyStatus}); err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("anyStatus: resp = %v, err = %v", resp, err)
+	}
+}
+
+func TestFieldsQuery(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[{"id": 1, "title": "a"}]`)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	ctx := context.Background()
+
+	posts, err := c.GetAllPostsContext(ctx, "id", "title")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 || posts[0].ID != 1 || posts[0].Title != "a" || posts[0].UserID != 0 || posts[0].Body != "" {
+		t.Errorf("posts = %+v; 빠진 필드는 0 값이어야 함", posts)
+	}
+	if _, err := c.GetPostsByUser(ctx, 2, "createdAt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetPostsQuery(ctx, PostQuery{}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"_fields=id%2Ctitle", "_fields=createdAt&userId=2", ""}
+	if !reflect.DeepEqual(queries, want) {
+		t.Errorf("queries = %q; want %q", queries, want)
+	}
+
+	// 태그에 없는 이름은 요청을 보내기 전에 거부
+	for _, bad := range []string{"Title", "CreatedAt", ""} {
+		if _, err := c.GetAllPostsContext(ctx, bad); err == nil {
+			t.Errorf("필드 %q: 오류가 없음", bad)
+		}
+	}
+	if len(queries) != len(want) {
+		t.Errorf("잘못된 필드로 요청이 전송됨: %q", queries)
+	}
+}
+
+func TestAdaptiveLimiter(t *testing.T) {
+	l := newAdaptiveLimiter(1, 4)
+	ctx := context.Background()
+	ms := time.Millisecond
+
+	// 빠른 성공이 한 바퀴 쌓일 때마다 약 1씩 증가 (1 → 2 → 2.5 → 2.9 → 3.24)
+	for range 4 {
+		gen, _ := l.Acquire(ctx)
+		l.Release(gen, 10*ms, nil)
+	}
+	if got := l.Limit(); got != 3 {
+		t.Fatalf("증가 후 limit = %d; want 3", got)
+	}
+
+	// 같은 세대에 시작한 요청들이 모두 429 를 받아도 한 번만 절반으로 줄임
+	gens := make([]uint64, 3)
+	for i := range gens {
+		gens[i], _ = l.Acquire(ctx)
+	}
+	tooMany := &HTTPError{StatusCode: http.StatusTooManyRequests}
+	for _, gen := range gens {
+		l.Release(gen, 10*ms, fmt.Errorf("감쌈: %w", tooMany))
+	}
+	if got := l.Limit(); got != 1 {
+		t.Fatalf("429 후 limit = %d; want 1 (3/2 내림)", got)
+	}
+
+	// 평균보다 훨씬 느린 성공도 혼잡으로 보고, 404 같은 오류는 한도를 바꾸지 않음
+	gen, _ := l.Acquire(ctx)
+	l.Release(gen, 10*ms, nil)
+	gen, _ = l.Acquire(ctx)
+	l.Release(gen, 10*ms, nil)
+	if got := l.Limit(); got != 2 {
+		t.Fatalf("limit = %d; want 2", got)
+	}
+	gen, _ = l.Acquire(ctx)
+	l.Release(gen, 10*ms, &HTTPError{StatusCode: http.StatusNotFound})
+	if got := l.Limit(); got != 2 {
+		t.Errorf("404 후 limit = %d; want 2", got)
+	}
+	gen, _ = l.Acquire(ctx)
+	l.Release(gen, 100*ms, nil)
+	if got := l.Limit(); got != 1 {
+		t.Errorf("느린 응답 후 limit = %d; want 1", got)
+	}
+
+	// 한도가 찬 동안에는 기다리고, ctx 가 취소되면 포기
+	held, _ := l.Acquire(ctx)
+	cctx, cancel := context.WithTimeout(ctx, 20*ms)
+	defer cancel()
+	if _, err := l.Acquire(cctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("가득 찬 상태의 Acquire: err = %v", err)
+	}
+	l.Release(held, 10*ms, nil)
+}
+
+func TestGetPostsAdaptiveConcurrency(t *testing.T) {
+	var inflight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inflight.Add(1)
+		defer inflight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		if n > 3 {
+			w.WriteHeader(http.StatusTooManyRequests) // 동시 3개를 넘으면 거절하는 서버
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+		id := strings.TrimPrefix(r.URL.Path, "/posts/")
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"userId": 1, "id": `+id+`, "title": "t"}`)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, 5*time.Second).WithAdaptiveConcurrency(1, 3)
+	ids := make([]int, 30)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+	posts, err := c.GetPosts(context.Background(), ids, 100, true) // 고정 concurrency 는 무시됨
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != len(ids) || posts[29].ID != 30 {
+		t.Errorf("posts 길이 = %d", len(posts))
+	}
+	if got := peak.Load(); got > 3 {
+		t.Errorf("최대 동시 요청 = %d; want ≤ 3", got)
+	}
+	if got := c.adaptive.Limit(); got < 2 {
+		t.Errorf("지연이 고른 서버에서 limit = %d; 증가해야 함", got)
+	}
+}
+
+func TestSavePostFilesResume(t *testing.T) {
+	var mu sync.Mutex
+	var fetched []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/posts" {
+			if r.URL.Query().Get("_fields") != "id" {
+				t.Errorf("목록 요청 쿼리 = %q; want _fields=id", r.URL.RawQuery)
+			}
+			io.WriteString(w, `[{"id": 1}, {"id": 2}, {"id": 3}]`)
+			return
+		}
+		id := strings.TrimPrefix(r.URL.Path, "/posts/")
+		mu.Lock()
+		fetched = append(fetched, id)
+		mu.Unlock()
+		if id == "3" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, `{"userId": 1, "id": `+id+`, "title": "새 글"}`)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "post_1.json"), []byte(`{"id": 1, "title": "이전 실행"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := newTestClient(t, srv.URL, 5*time.Second)
+
+	summary, err := savePostFiles(context.Background(), c, dir, false, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := saveSummary{Fetched: []int{2}, Skipped: []int{1}, Failed: []int{3}}
+	if !reflect.DeepEqual(summary, want) {
+		t.Errorf("summary = %+v; want %+v", summary, want)
+	}
+	slices.Sort(fetched)
+	if !reflect.DeepEqual(fetched, []string{"2", "3"}) {
+		t.Errorf("가져온 id = %v; 이미 있는 1 은 건너뛰어야 함", fetched)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "post_1.json")); !strings.Contains(string(b), "이전 실행") {
+		t.Errorf("post_1.json 이 덮어써짐: %s", b)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "post_3.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("실패한 게시물 파일이 남음: err = %v", err)
+	}
+	if entries, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(entries) != 0 {
+		t.Errorf("임시 파일이 남음: %v", entries)
+	}
+
+	summary, err = savePostFiles(context.Background(), c, dir, true, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(summary.Fetched, []int{1, 2}) || len(summary.Skipped) != 0 {
+		t.Errorf("force: summary = %+v", summary)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "post_1.json")); !strings.Contains(string(b), "새 글") {
+		t.Errorf("force 인데 post_1.json 이 그대로: %s", b)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// 빠진 필드는 기본값 유지, 명시한 0 은 그대로 0
+	cfg, err := loadConfig(write("partial.json", `{"baseURL": "https://staging.example.com", "retries": 0, "headers": {"X-Env": "staging"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := defaultConfig()
+	want.BaseURL, want.Retries, want.Headers = "https://staging.example.com", 0, map[string]string{"X-Env": "staging"}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("cfg = %+v; want %+v", cfg, want)
+	}
+
+	for name, content := range map[string]string{
+		"bad-timeout.json":  `{"timeout": "-1s"}`,
+		"bad-url.json":      `{"baseURL": "api.example.com"}`,
+		"bad-format.json":   `{"format": "xml"}`,
+		"bad-numbers.json":  `{"retries": -1, "concurrency": 0}`,
+		"bad-header.json":   `{"headers": {"X Bad": "1"}}`,
+		"unknown-key.json":  `{"timout": "5s"}`,
+		"not-json.json":     `timeout: 5s`,
+		"wrong-types.json":  `{"retries": "3"}`,
+		"timeout-only.json": `{"timeout": "250ms"}`,
+	} {
+		cfg, err := loadConfig(write(name, content))
+		if name == "timeout-only.json" {
+			if err != nil || cfg.Timeout != "250ms" || cfg.Format != formatText {
+				t.Errorf("%s: cfg = %+v, err = %v", name, cfg, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: 오류가 없음", name)
+		}
+	}
+	// 잘못된 항목은 한꺼번에 보고
+	if _, err := loadConfig(filepath.Join(dir, "bad-numbers.json")); err == nil || !strings.Contains(err.Error(), "retries") || !strings.Contains(err.Error(), "concurrency") {
+		t.Errorf("err = %v; retries 와 concurrency 를 모두 보고해야 함", err)
+	}
+	if _, err := loadConfig(filepath.Join(dir, "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("없는 파일: err = %v", err)
+	}
+
+	t.Setenv(baseURLEnv, "")
+	if got := resolveBaseURL("", "https://file.example.com/"); got != "https://file.example.com" {
+		t.Errorf("resolveBaseURL(파일) = %q", got)
+	}
+	if got := resolveBaseURL("https://flag.example.com", "https://file.example.com"); got != "https://flag.example.com" {
+		t.Errorf("resolveBaseURL(플래그) = %q", got)
+	}
+}
+
+// doerFunc 는 함수를 Doer 로 쓰기 위한 어댑터입니다.
+type doerFunc func(*http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestShortRead(t *testing.T) {
+	// 실제 전송 계층은 Content-Length 보다 짧으면 스스로 오류를 내므로, 길이를 마음대로 정하는 Doer 로 흉내 냄
+	respond := func(body string, contentLength int64) *Client {
+		return NewClientWithDoer("https://api.example.com", doerFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode:    http.StatusOK,
+				Header:        http.Header{"Content-Type": {"application/json"}},
+				Body:          io.NopCloser(strings.NewReader(body)),
+				ContentLength: contentLength,
+				Request:       req,
+			}, nil
+		}))
+	}
+	post := `{"userId": 1, "id": 1, "title": "t"}` + "\n"
+	list := `[` + strings.TrimSpace(post) + `]`
+
+	for _, tc := range []struct {
+		name          string
+		body          string
+		contentLength int64
+		wantErr       bool
+	}{
+		{"일치", post, int64(len(post)), false},
+		{"길이 없음(청크)", post, -1, false},
+		{"짧음", post, int64(len(post)) + 40, true},
+		{"김", post, int64(len(post)) - 1, true},
+	} {
+		c := respond(tc.body, tc.contentLength)
+		_, err := c.GetPost(1)
+		if got := errors.Is(err, ErrShortRead); got != tc.wantErr {
+			t.Errorf("%s: GetPost err = %v; ErrShortRead 여부 %v", tc.name, err, tc.wantErr)
+		}
+		_, err = c.WithStrictDecoding(true).GetPost(1)
+		if got := errors.Is(err, ErrShortRead); got != tc.wantErr {
+			t.Errorf("%s: 엄격 모드 GetPost err = %v; ErrShortRead 여부 %v", tc.name, err, tc.wantErr)
+		}
+	}
+
+	err := respond(list, int64(len(list))+10).StreamPosts(context.Background(), func(Post) error { return nil })
+	if !errors.Is(err, ErrShortRead) {
+		t.Errorf("StreamPosts err = %v; want ErrShortRead", err)
+	}
+	if err := respond(list, int64(len(list))).StreamPosts(context.Background(), func(Post) error { return nil }); err != nil {
+		t.Errorf("StreamPosts(일치) err = %v", err)
+	}
+}
+
+func TestPrintToWriter(t *testing.T) {
+	posts := []Post{{UserID: 1, ID: 1, Title: "a <b>"}, {UserID: 2, ID: 2, Title: "c"}}
+	for _, format := range []string{formatText, formatJSON, formatTable, formatCSV, formatJSONL} {
+		var stdout, file bytes.Buffer
+		w := io.MultiWriter(&stdout, &file)
+		if err := printPosts(w, format, posts); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if err := printPost(w, format, &posts[0]); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if stdout.Len() == 0 || stdout.String() != file.String() {
+			t.Errorf("%s: 두 출력이 다름\nstdout: %q\nfile:   %q", format, stdout.String(), file.String())
+		}
+		if !strings.Contains(stdout.String(), "a <b>") {
+			t.Errorf("%s: 출력에 제목이 없음: %q", format, stdout.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	printPostFields(&buf, posts, []string{"id", "title"})
+	if want := "1\ta <b>\n2\tc\n"; buf.String() != want {
+		t.Errorf("printPostFields = %q; want %q", buf.String(), want)
+	}
+}
+
+func TestGetPostsSince(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[{"id": 3}, {"id": 1}, {"id": 5}, {"id": 4}, {"id": 2}]`) // 서버 필터는 무시함
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	ctx := context.Background()
+	ids := func(posts []Post) []int {
+		var out []int
+		for _, p := range posts {
+			out = append(out, p.ID)
+		}
+		return out
+	}
+
+	posts, last, err := c.GetPostsSince(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids(posts), []int{3, 4, 5}) || last != 5 {
+		t.Errorf("ids = %v, last = %d; want [3 4 5], 5", ids(posts), last)
+	}
+
+	// 새 게시물이 없으면 빈 슬라이스와 sinceID 그대로
+	posts, last, err = c.WithSinceIDParam("id_gt").GetPostsSince(ctx, 5)
+	if err != nil || posts == nil || len(posts) != 0 || last != 5 {
+		t.Errorf("posts = %v, last = %d, err = %v; want [], 5, nil", posts, last, err)
+	}
+	if want := []string{"", "id_gt=5"}; !reflect.DeepEqual(queries, want) {
+		t.Errorf("queries = %q; want %q", queries, want)
+	}
+
+	if _, _, err := c.GetPostsSince(ctx, -1); err == nil {
+		t.Error("음수 sinceID: 오류가 없음")
+	}
+}
+
+func TestDNSErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		dnsErr       *net.DNSError
+		wantAttempts int32
+		wantTimeout  bool
+	}{
+		{"없는 호스트", &net.DNSError{Err: "no such host", Name: "nope.invalid", IsNotFound: true}, 1, false},
+		{"일시적 오류", &net.DNSError{Err: "server misbehaving", Name: "api.example.com", IsTemporary: true}, 3, false},
+		{"조회 시간 초과", &net.DNSError{Err: "i/o timeout", Name: "api.example.com", IsTimeout: true}, 3, true},
+	} {
+		var attempts atomic.Int32
+		c := NewClientWithDoer("https://api.example.com", doerFunc(func(req *http.Request) (*http.Response, error) {
+			attempts.Add(1)
+			return nil, &url.Error{Op: "Get", URL: req.URL.String(), Err: &net.OpError{Op: "dial", Net: "tcp", Err: tc.dnsErr}}
+		}))
+		c.MaxRetries = 2
+		c.WithBackoff(ConstantBackoff{})
+
+		_, err := c.GetPost(1)
+		if !errors.Is(err, ErrDNS) {
+			t.Errorf("%s: err = %v; want ErrDNS", tc.name, err)
+		}
+		if got := errors.Is(err, ErrTimeout); got != tc.wantTimeout {
+			t.Errorf("%s: ErrTimeout 여부 = %v; want %v", tc.name, got, tc.wantTimeout)
+		}
+		if !tc.wantTimeout && !errors.Is(err, ErrConnection) {
+			t.Errorf("%s: err = %v; ErrConnection 도 감싸야 함", tc.name, err)
+		}
+		if got := attempts.Load(); got != tc.wantAttempts {
+			t.Errorf("%s: 시도 횟수 = %d; want %d", tc.name, got, tc.wantAttempts)
+		}
+	}
+
+	// 연결 거부는 ErrDNS 가 아님
+	c := NewClientWithDoer("https://api.example.com", doerFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, &url.Error{Op: "Get", URL: req.URL.String(), Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+	}))
+	c.MaxRetries = 0
+	if _, err := c.GetPost(1); !errors.Is(err, ErrConnection) || errors.Is(err, ErrDNS) {
+		t.Errorf("연결 거부: err = %v; want ErrConnection 이고 ErrDNS 아님", err)
+	}
+}
+
+func TestMaxPosts(t *testing.T) {
+	items := make([]string, 50)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"userId": 1, "id": %d, "title": "t%d"}`, i+1, i+1)
+	}
+	_, c := newClientTestServer(t, map[string]string{"/posts": "[" + strings.Join(items, ",") + "]"})
+
+	var seen []int
+	err := c.StreamPosts(context.Background(), func(p Post) error {
+		seen = append(seen, p.ID)
+		if len(seen) == 2 {
+			return ErrStopStream
+		}
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(seen, []int{1, 2}) {
+		t.Errorf("seen = %v, err = %v; want [1 2], nil", seen, err)
+	}
+
+	posts := []Post{{ID: 1, Title: "a"}, {ID: 2, Title: "b"}, {ID: 3, Title: "c"}}
+	for _, tc := range []struct{ n, want int }{{0, 3}, {-1, 3}, {2, 2}, {3, 3}, {10, 3}} {
+		if got := len(firstPosts(posts, tc.n)); got != tc.want {
+			t.Errorf("firstPosts(%d) 길이 = %d; want %d", tc.n, got, tc.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	printPostSummary(&buf, posts, 2)
+	if out := buf.String(); !strings.Contains(out, "ID: 2, 제목: b") || strings.Contains(out, "ID: 3") || !strings.Contains(out, "총 3개의 게시물 중 2개만") {
+		t.Errorf("printPostSummary(2) = %q", out)
+	}
+	buf.Reset()
+	printPostSummary(&buf, posts, 5)
+	if out := buf.String(); !strings.Contains(out, "ID: 3, 제목: c") || strings.Contains(out, "개만 출력") {
+		t.Errorf("printPostSummary(5) = %q; 잘라 내지 않았으면 안내 문구가 없어야 함", out)
+	}
+}
+
+func TestCreatePosts(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		seen []string // 서버가 받은 제목
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p Post
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		seen = append(seen, p.Title)
+		mu.Unlock()
+		if p.Title == "bad" {
+			http.Error(w, "실패", http.StatusInternalServerError)
+			return
+		}
+		p.ID = 100 + p.UserID
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(p)
+	}))
+	t.Cleanup(srv.Close)
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	c.MaxRetries = 0
+	ctx := context.Background()
+
+	posts := []Post{{UserID: 1, Title: "a"}, {UserID: 2, Title: "bad"}, {UserID: 3, Title: "c"}, {UserID: 4, Title: "d"}}
+
+	// stopOnError=false: 끝까지 보내고 실패만 모아서 반환, 결과는 입력 순서 유지
+	created, err := c.CreatePosts(ctx, posts, 2, false)
+	if err == nil || !isHTTPStatus(http.StatusInternalServerError)(err) || !strings.Contains(err.Error(), "posts[1]") {
+		t.Fatalf("stopOnError=false: err = %v; want posts[1] 의 500 오류", err)
+	}
+	if len(created) != len(posts) {
+		t.Fatalf("stopOnError=false: 결과 %d개; want %d", len(created), len(posts))
+	}
+	for i, want := range []int{101, 0, 103, 104} {
+		if created[i].ID != want {
+			t.Errorf("created[%d].ID = %d; want %d", i, created[i].ID, want)
+		}
+	}
+	if len(seen) != len(posts) {
+		t.Errorf("stopOnError=false: 요청 %d회 (%v); want %d", len(seen), seen, len(posts))
+	}
+
+	// stopOnError=true: 동시성 1 이면 실패 이후의 게시물은 서버까지 가지 않음
+	seen = nil
+	created, err = c.CreatePosts(ctx, posts, 1, true)
+	if created != nil || !isHTTPStatus(http.StatusInternalServerError)(err) || !strings.Contains(err.Error(), "posts[1]") {
+		t.Fatalf("stopOnError=true: created = %v, err = %v; want nil, posts[1] 의 500 오류", created, err)
+	}
+	if !reflect.DeepEqual(seen, []string{"a", "bad"}) {
+		t.Errorf("stopOnError=true: 서버가 받은 제목 = %v; want [a bad]", seen)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"userId": 1, "id": 1, "title": "캐시", "body": "본문"}`))
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL, 5*time.Second).WithCache(time.Minute)
+
+	getPost := func(wantHits int32) {
+		t.Helper()
+		post, err := c.GetPost(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.Title != "캐시" {
+			t.Errorf("Title = %q", post.Title)
+		}
+		if got := hits.Load(); got != wantHits {
+			t.Errorf("서버 호출 %d 번; want %d", got, wantHits)
+		}
+	}
+
+	getPost(1)
+	getPost(1) // TTL 안의 두 번째 요청은 메모리에서 읽음
+
+	c.ClearCache()
+	getPost(2) // 비운 뒤에는 다시 네트워크로 가져옴
+	getPost(2)
+
+	// 만료된 항목은 다시 네트워크로 가져옴
+	c.WithCache(time.Nanosecond)
+	getPost(3)
+	time.Sleep(time.Millisecond)
+	getPost(4)
+}
+
+func TestRateLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	// burst 개까지는 바로 통과하고, 그다음은 약 1/rps 만큼 기다림
+	l := newRateLimiter(20, 3)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d > 20*time.Millisecond {
+		t.Errorf("burst 3 회에 %v 걸림; 바로 통과해야 합니다", d)
+	}
+	start = time.Now()
+	if err := l.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 40*time.Millisecond || d > 250*time.Millisecond {
+		t.Errorf("네 번째 Wait 가 %v 기다림; want 약 50ms", d)
+	}
+
+	// 기다리는 중에 ctx 가 취소되면 바로 ctx 오류를 반환
+	l = newRateLimiter(1, 1)
+	l.Wait(ctx)
+	cctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if err := l.Wait(cctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("취소된 Wait: err = %v; want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("취소된 Wait 가 %v 뒤에 반환; 1초를 다 기다리면 안 됩니다", d)
+	}
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < -0.5 {
+		t.Errorf("취소된 Wait 뒤 토큰 = %.2f; 예약한 토큰을 돌려줘야 합니다", tokens)
+	}
+
+	// Client 를 거쳐도 기다리는 중의 취소가 바로 전달됨
+	_, rc := newClientTestServer(t, map[string]string{"/posts/1": `{"userId": 1, "id": 1, "title": "t"}`})
+	rc.WithRateLimit(0.5, 1)
+	if _, err := rc.GetPost(1); err != nil {
+		t.Fatal(err)
+	}
+	cctx, cancel = context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if _, err := rc.GetPostContext(cctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("제한기 대기 중 취소: err = %v; want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("제한기 대기 중 취소가 %v 뒤에 반환", d)
+	}
+
+	// rps 가 0 이하이면 제한기를 끄고 요청을 그대로 보냄
+	_, c := newClientTestServer(t, map[string]string{"/posts/1": `{"userId": 1, "id": 1, "title": "t"}`})
+	c.WithRateLimit(1, 1).WithRateLimit(0, 1)
+	if c.limiter != nil {
+		t.Fatal("WithRateLimit(0) 뒤에도 limiter 가 남아 있습니다")
+	}
+	start = time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := c.GetPost(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("제한 없이 5 회 요청에 %v 걸림", d)
+	}
+}
+
+func TestMaxRetryElapsed(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "점검 중", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	// 재시도 횟수는 넉넉하지만 30ms 간격으로는 100ms 안에 몇 번만 시도할 수 있음
+	c := newTestClient(t, srv.URL, 5*time.Second).
+		WithBackoff(ConstantBackoff{Delay: 30 * time.Millisecond}).
+		WithMaxRetryElapsed(100 * time.Millisecond)
+	c.MaxRetries = 100
+
+	start := time.Now()
+	_, err := c.GetPost(1)
+	elapsed := time.Since(start)
+	if !isHTTPStatus(http.StatusServiceUnavailable)(err) {
+		t.Errorf("err = %v; want 503 HTTPError", err)
+	}
+	if n := calls.Load(); n < 2 || n > 5 {
+		t.Errorf("서버 호출 %d 번; want 2~5 번 (MaxRetries 전에 시간 상한으로 멈춰야 함)", n)
+	}
+	if elapsed > time.Second {
+		t.Errorf("GetPost 가 %v 걸림; 100ms 상한을 지켜야 합니다", elapsed)
+	}
+}
+
+func TestBodyTee(t *testing.T) {
+	const raw = "{ \"userId\": 1,\n  \"id\": 1, \"title\": \"복사\", \"body\": \"본문\" }\n"
+	_, c := newClientTestServer(t, map[string]string{"/posts/1": raw, "/posts/2": `{"id": "깨짐"`})
+	var buf bytes.Buffer
+	c.WithBodyTee(&buf)
+
+	post, err := c.GetPost(1)
+	if err != nil {
+		t.Fatalf("GetPost: %v", err)
+	}
+	if post.ID != 1 || post.Title != "복사" {
+		t.Errorf("post = %+v; 복사해도 디코딩은 그대로여야 합니다", post)
+	}
+	if got := buf.String(); got != raw {
+		t.Errorf("tee = %q; want 원본 바이트 %q", got, raw)
+	}
+
+	// 디코딩에 실패한 본문도 그대로 남음
+	buf.Reset()
+	if _, err := c.GetPost(2); !isDecodeError(err) {
+		t.Errorf("GetPost(2): err = %v; want DecodeError", err)
+	}
+	if got := buf.String(); got != `{"id": "깨짐"` {
+		t.Errorf("실패한 본문 tee = %q", got)
+	}
+}
+
+func TestGetAllPostsPaged(t *testing.T) {
+	tests := []struct {
+		name      string
+		n         int  // 서버에 있는 게시물 수
+		withTotal bool // X-Total-Count 헤더를 보낼지
+		wantPages []int
+	}{
+		{"짧은 페이지에서 멈춤", 7, false, []int{1, 2, 3}},
+		{"빈 페이지에서 멈춤", 6, false, []int{1, 2, 3}},
+		{"X-Total-Count 에 도달하면 멈춤", 6, true, []int{1, 2}},
+		{"게시물 없음", 0, false, []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu    sync.Mutex
+				pages []int
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				page, _ := strconv.Atoi(r.URL.Query().Get("_page"))
+				limit, _ := strconv.Atoi(r.URL.Query().Get("_limit"))
+				mu.Lock()
+				pages = append(pages, page)
+				mu.Unlock()
+
+				items := []Post{}
+				for id := (page-1)*limit + 1; id <= min(page*limit, tt.n); id++ {
+					items = append(items, Post{UserID: 1, ID: id})
+				}
+				if tt.withTotal {
+					w.Header().Set("X-Total-Count", strconv.Itoa(tt.n))
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(items)
+			}))
+			defer srv.Close()
+
+			posts, err := newTestClient(t, srv.URL, 5*time.Second).GetAllPostsPaged(context.Background(), 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if posts == nil || len(posts) != tt.n {
+				t.Fatalf("게시물 %d개 (nil=%v); want %d", len(posts), posts == nil, tt.n)
+			}
+			for i, p := range posts {
+				if p.ID != i+1 {
+					t.Errorf("posts[%d].ID = %d; want %d", i, p.ID, i+1)
+				}
+			}
+			if !reflect.DeepEqual(pages, tt.wantPages) {
+				t.Errorf("요청한 페이지 = %v; want %v", pages, tt.wantPages)
+			}
+		})
+	}
+
+	if _, err := NewClientWithDoer("https://api.example.com", nil).GetAllPostsPaged(context.Background(), 0); err == nil {
+		t.Error("pageSize 0: 오류가 없습니다")
+	}
+}
+
+func TestOnProgress(t *testing.T) {
+	routes := map[string]string{}
+	ids := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	for _, id := range ids {
+		if id != 5 { // 5 는 404 (실패한 요청도 진행률에 포함되어야 함)
+			routes[fmt.Sprintf("/posts/%d", id)] = fmt.Sprintf(`{"userId": 1, "id": %d, "title": "t"}`, id)
+		}
+	}
+	_, c := newClientTestServer(t, routes)
+	c.MaxRetries = 0
+
+	var done, totals []int // OnProgress 는 직렬로 호출되므로 잠금 없이 모음
+	c.OnProgress = func(d, total int) {
+		done = append(done, d)
+		totals = append(totals, total)
+	}
+	if _, err := c.GetPosts(context.Background(), ids, 4, false); !isHTTPStatus(http.StatusNotFound)(err) {
+		t.Errorf("err = %v; want 404 HTTPError", err)
+	}
+
+	for i := range ids {
+		if i >= len(done) || done[i] != i+1 || totals[i] != len(ids) {
+			t.Fatalf("진행률 done = %v, total = %v; want done 1..%d, total 항상 %d", done, totals, len(ids), len(ids))
+		}
+	}
+	if len(done) != len(ids) {
+		t.Errorf("OnProgress %d 번 호출; want %d", len(done), len(ids))
+	}
+}
+
+func TestResolveToken(t *testing.T) {
+	tests := []struct {
+		token, b64 string
+		want       string
+		wantErr    bool
+	}{
+		{"", "", "", false},
+		{"plain", "", "plain", false},
+		{"  plain\n", "", "plain", false},
+		{"plain", "c2VjcmV0", "plain", false}, // API_TOKEN 이 우선
+		{"", "c2VjcmV0", "secret", false},
+		{"", "c2VjcmV0Cg==", "secret", false}, // echo 로 붙은 줄바꿈 제거
+		{"  ", "c2VjcmV0", "secret", false},   // 공백뿐인 API_TOKEN 은 없는 것으로 봄
+		{"", "!!not-base64!!", "", true},
+		{"", "IAo=", "", true}, // 디코딩 결과가 공백뿐
+	}
+	for _, tt := range tests {
+		t.Setenv(tokenEnv, tt.token)
+		t.Setenv(tokenB64Env, tt.b64)
+		got, err := resolveToken()
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("resolveToken(%q, %q) = %q, %v; want %q, 오류 %v", tt.token, tt.b64, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSearchPostsByTitle(t *testing.T) {
+	_, c := newClientTestServer(t, map[string]string{"/posts": `[
+		{"userId": 1, "id": 1, "title": "Go 언어 입문"},
+		{"userId": 1, "id": 2, "title": "HTTP 클라이언트"},
+		{"userId": 2, "id": 3, "title": "go 루틴과 채널"}
+	]`})
+	tests := []struct {
+		substr string
+		want   []int
+	}{
+		{"go", []int{1, 3}},
+		{"GO", []int{1, 3}}, // 대소문자 구분 안 함
+		{"클라이언트", []int{2}},
+		{"", []int{1, 2, 3}},
+		{"없는 제목", []int{}},
+	}
+	for _, tt := range tests {
+		posts, err := c.SearchPostsByTitle(context.Background(), tt.substr)
+		if err != nil {
+			t.Fatalf("SearchPostsByTitle(%q): %v", tt.substr, err)
+		}
+		got := []int{}
+		for _, p := range posts {
+			got = append(got, p.ID)
+		}
+		if posts == nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SearchPostsByTitle(%q) = %v (nil=%v), want %v", tt.substr, got, posts == nil, tt.want)
+		}
+	}
+}
+
+func TestCreatePostForm(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if ct := r.Header.Get("Content-Type"); ct != "application/x-www-form-urlencoded" {
+			http.Error(w, "Content-Type = "+ct, http.StatusUnsupportedMediaType)
+			return
+		}
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"userId": %s, "id": 101, "title": %q}`, r.PostForm.Get("userId"), r.PostForm.Get("title"))
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL, 5*time.Second)
+
+	tests := []struct {
+		values  url.Values
+		wantErr string // 비어 있으면 성공 기대
+	}{
+		{url.Values{"title": {"폼 글"}, "userId": {"3"}, "body": {"내용"}}, ""},
+		{url.Values{"title": {"폼 글"}, "userId": {"3"}}, ""}, // body 는 선택
+		{url.Values{"userId": {"3"}}, "title"},
+		{url.Values{"title": {"   "}, "userId": {"3"}}, "title"},
+		{url.Values{"title": {"폼 글"}}, "userId"},
+		{url.Values{}, "title"},
+	}
+	for _, tt := range tests {
+		calls.Store(0)
+		post, err := c.CreatePostForm(context.Background(), tt.values)
+		if tt.wantErr == "" {
+			if err != nil || post.ID != 101 || post.UserID != 3 || post.Title != "폼 글" {
+				t.Errorf("CreatePostForm(%v) = %+v, %v", tt.values, post, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("CreatePostForm(%v): err = %v; want %s 누락 오류", tt.values, err, tt.wantErr)
+		}
+		if n := calls.Load(); n != 0 {
+			t.Errorf("CreatePostForm(%v): 검증 실패인데 요청을 %d 번 보냄", tt.values, n)
+		}
+	}
+}
+
+func TestPrintCSV(t *testing.T) {
+	const header = "id,userId,title,body\n"
+	tests := []struct {
+		posts []Post
+		want  string
+	}{
+		{nil, header},
+		{[]Post{{ID: 1, UserID: 2, Title: "제목", Body: "본문"}}, header + "1,2,제목,본문\n"},
+		{[]Post{{ID: 3, UserID: 1, Title: "a, b", Body: "첫 줄\n둘째 줄"}}, header + "3,1,\"a, b\",\"첫 줄\n둘째 줄\"\n"},
+		{[]Post{{ID: 4, UserID: 1, Title: `"인용"`, Body: ""}}, header + "4,1,\"\"\"인용\"\"\",\n"},
+		{[]Post{{ID: 5, UserID: 1, Title: "x"}, {ID: 6, UserID: 2, Title: "y"}}, header + "5,1,x,\n6,2,y,\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := printCSV(&buf, tt.posts); err != nil {
+			t.Fatalf("printCSV(%v): %v", tt.posts, err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("printCSV(%v) = %q, want %q", tt.posts, got, tt.want)
+		}
+	}
+}
+
+func TestDedupePosts(t *testing.T) {
+	tests := []struct {
+		in   []Post
+		want []Post
+	}{
+		{nil, []Post{}},
+		{[]Post{{ID: 1}, {ID: 2}}, []Post{{ID: 1}, {ID: 2}}},
+		{[]Post{{ID: 2, Title: "처음"}, {ID: 1}, {ID: 2, Title: "중복"}, {ID: 3}, {ID: 1}}, []Post{{ID: 2, Title: "처음"}, {ID: 1}, {ID: 3}}},
+		{[]Post{{ID: 5}, {ID: 5}, {ID: 5}}, []Post{{ID: 5}}},
+	}
+	for _, tt := range tests {
+		if got := DedupePosts(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("DedupePosts(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	// 입력 슬라이스는 바꾸지 않음
+	in := []Post{{ID: 1}, {ID: 1}, {ID: 2}}
+	DedupePosts(in)
+	if !reflect.DeepEqual(in, []Post{{ID: 1}, {ID: 1}, {ID: 2}}) {
+		t.Errorf("DedupePosts 가 입력을 바꿈: %v", in)
+	}
+}
+
+func TestPostQueryValues(t *testing.T) {
+	tests := []struct {
+		q    PostQuery
+		want string
+	}{
+		{PostQuery{}, ""},
+		{PostQuery{UserID: 3}, "userId=3"},
+		{PostQuery{Page: 2, Limit: 10}, "_limit=10&_page=2"},
+		{PostQuery{SortBy: "title", Order: "desc"}, "_order=desc&_sort=title"},
+		{PostQuery{UserID: 1, Page: 1, Limit: 5, SortBy: "id", Order: "asc"}, "_limit=5&_order=asc&_page=1&_sort=id&userId=1"},
+		{PostQuery{SortBy: "제목 순"}, "_sort=%EC%A0%9C%EB%AA%A9+%EC%88%9C"},
+	}
+	for _, tt := range tests {
+		if got := tt.q.Values().Encode(); got != tt.want {
+			t.Errorf("%+v.Values() = %q, want %q", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"id", []string{"id"}},
+		{"id,title", []string{"id", "title"}},
+		{" title , body ", []string{"title", "body"}},
+		{"userId,,id,", []string{"userId", "id"}}, // 빈 항목은 무시
+		{"body,id", []string{"body", "id"}},       // 지정한 순서 유지
+	}
+	for _, tt := range tests {
+		if got, err := parseFields(tt.in); err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseFields(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", " , ", "name", "id,Title", "id,userid"} {
+		if got, err := parseFields(in); err == nil {
+			t.Errorf("parseFields(%q) = %v 는 오류여야 합니다", in, got)
+		}
+	}
+}
+
+func TestSavePostFilesWritesJSON(t *testing.T) {
+	_, c := newClientTestServer(t, map[string]string{
+		"/posts":   `[{"id": 1}, {"id": 2}]`,
+		"/posts/1": `{"userId": 1, "id": 1, "title": "첫 글", "body": "첫 줄\n둘째 줄"}`,
+		"/posts/2": `{"userId": 2, "id": 2, "title": "둘째 글", "body": "<b>&</b>"}`,
+	})
+
+	// 없는 하위 디렉터리도 만들어야 함
+	dir := filepath.Join(t.TempDir(), "export", "posts")
+	summary, err := savePostFiles(context.Background(), c, dir, false, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(summary.Fetched, []int{1, 2}) || len(summary.Failed) != 0 {
+		t.Errorf("summary = %+v", summary)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if !reflect.DeepEqual(names, []string{"post_1.json", "post_2.json"}) {
+		t.Errorf("저장된 파일 = %v", names)
+	}
+
+	for _, want := range []Post{
+		{UserID: 1, ID: 1, Title: "첫 글", Body: "첫 줄\n둘째 줄"},
+		{UserID: 2, ID: 2, Title: "둘째 글", Body: "<b>&</b>"},
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("post_%d.json", want.ID)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Post
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("post_%d.json 이 올바른 JSON 이 아닙니다: %v\n%s", want.ID, err, b)
+		}
+		if got != want {
+			t.Errorf("post_%d.json = %+v; want %+v", want.ID, got, want)
+		}
+	}
+}
+
+func TestLoadPostFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	p, err := loadPostFile(write("ok.json", `{"userId": 2, "title": "파일 글", "body": "내용"}`))
+	if err != nil {
+		t.Fatalf("올바른 파일: %v", err)
+	}
+	if p.UserID != 2 || p.ID != 0 || p.Title != "파일 글" || p.Body != "내용" {
+		t.Errorf("loadPostFile = %+v", p)
+	}
+
+	tests := []struct {
+		name, content string
+		wantErr       string
+	}{
+		{"invalid.json", `{"userId": 2, "title": `, "JSON 이 올바르지 않습니다"},
+		{"array.json", `[{"userId": 2, "title": "t"}]`, "JSON 이 올바르지 않습니다"},
+		{"unknown.json", `{"userId": 2, "title": "t", "author": "x"}`, `"author"`},
+		{"notitle.json", `{"userId": 2, "body": "내용"}`, "title"},
+		{"nouser.json", `{"title": "t"}`, "userId"},
+	}
+	for _, tt := range tests {
+		if _, err := loadPostFile(write(tt.name, tt.content)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("loadPostFile(%s): err = %v; want %q 포함", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if _, err := loadPostFile(filepath.Join(dir, "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("없는 파일: err = %v; want os.ErrNotExist", err)
+	}
+}
+
+func TestBatchCancelKeepsPartialResults(t *testing.T) {
+	routes := map[string]string{}
+	for id := 1; id <= 4; id++ {
+		routes[fmt.Sprintf("/posts/%d", id)] = fmt.Sprintf(`{"userId": 1, "id": %d, "title": "t"}`, id)
+	}
+	_, c := newClientTestServer(t, routes)
+	c.MaxRetries = 0
+
+	// 일괄 조회 도중 취소 (Ctrl-C 와 같음): 끝난 게시물은 그대로 돌려받아야 함
+	for _, cancelAt := range []int{1, 4} {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.OnProgress = func(done, total int) {
+			if done == cancelAt {
+				cancel()
+			}
+		}
+		posts, err := c.GetPosts(ctx, []int{1, 2, 3, 4}, 1, false)
+		cancel()
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("cancelAt=%d: err = %v; want context.Canceled", cancelAt, err)
+		}
+		if len(posts) != 4 || posts[0].ID != 1 || (cancelAt == 4 && posts[3].ID != 4) {
+			t.Errorf("cancelAt=%d: posts = %v; 끝난 게시물이 남아 있어야 합니다", cancelAt, posts)
+		}
+	}
+	c.OnProgress = nil
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p Post
+		json.NewDecoder(r.Body).Decode(&p)
+		if p.Title == "stop" {
+			cancel()
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"userId": 1, "id": %d, "title": %q}`, 100+len(p.Title), p.Title)
+	}))
+	defer srv.Close()
+	wc := newTestClient(t, srv.URL, 5*time.Second)
+	wc.MaxRetries = 0
+
+	created, err := wc.CreatePosts(ctx, []Post{{UserID: 1, Title: "a"}, {UserID: 1, Title: "bb"}, {UserID: 1, Title: "stop"}, {UserID: 1, Title: "d"}}, 1, false)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("CreatePosts: err = %v; want context.Canceled", err)
+	}
+	if len(created) != 4 || created[0].ID != 101 || created[1].ID != 102 || created[3].ID != 0 {
+		t.Errorf("CreatePosts: created = %v; 취소 전에 만든 게시물만 남아야 합니다", created)
+	}
+}
+
+func TestSavePostFilesFromList(t *testing.T) {
+	// _fields 를 무시하고 전체 게시물을 보내는 서버: 목록 요청 한 번으로 모두 저장
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[{"userId": 1, "id": 1, "title": "하나", "body": "b1"}, {"userId": 2, "id": 2, "title": "둘", "body": "b2"}, {"id": 3}]`)
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	c.MaxRetries = 0
+
+	dir := t.TempDir()
+	summary, err := savePostFiles(context.Background(), c, dir, false, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// id 3 은 필수 필드가 없어 /posts/3 을 다시 요청하지만, 이 서버는 배열을 돌려주므로 실패
+	want := saveSummary{Fetched: []int{1, 2}, Failed: []int{3}}
+	if !reflect.DeepEqual(summary, want) {
+		t.Errorf("summary = %+v; want %+v", summary, want)
+	}
+	if !reflect.DeepEqual(paths, []string{"/posts", "/posts/3"}) {
+		t.Errorf("요청한 경로 = %v; 완전한 게시물은 다시 가져오지 않아야 합니다", paths)
+	}
+	var got Post
+	if b, err := os.ReadFile(filepath.Join(dir, "post_2.json")); err != nil || json.Unmarshal(b, &got) != nil || got.Title != "둘" || got.Body != "b2" {
+		t.Errorf("post_2.json = %+v, %v", got, err)
+	}
+}
+
+func TestSavePostFilesCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/posts":
+			io.WriteString(w, `[{"id": 1}, {"id": 2}, {"id": 3}]`)
+		case "/posts/2": // Ctrl-C 처럼 도중에 취소
+			cancel()
+			<-r.Context().Done()
+		default:
+			id := strings.TrimPrefix(r.URL.Path, "/posts/")
+			io.WriteString(w, `{"userId": 1, "id": `+id+`, "title": "t"}`)
+		}
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	c.MaxRetries = 0
+
+	dir := t.TempDir()
+	summary, err := savePostFiles(ctx, c, dir, false, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v; want context.Canceled", err)
+	}
+	want := saveSummary{Fetched: []int{1}, Failed: []int{2, 3}}
+	if !reflect.DeepEqual(summary, want) {
+		t.Errorf("summary = %+v; want %+v (실제로 저장한 파일 기준)", summary, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "post_1.json")); err != nil {
+		t.Errorf("취소 전에 가져온 post_1.json 이 없습니다: %v", err)
+	}
+}
+
+func TestClientWriteMethods(t *testing.T) {
+	srv := newFixtureServer(t, map[string]fixture{
+		"/posts":   {status: http.StatusCreated, body: `{"userId":1,"id":101,"title":"새 글","body":"내용"}`},
+		"/posts/1": {body: `{"userId":1,"id":1,"title":"수정","body":"내용"}`},
+	})
+	c := newTestClient(t, srv.URL, 5*time.Second)
+	ctx := context.Background()
+
+	created, err := c.CreatePost(ctx, Post{UserID: 1, Title: "새 글", Body: "내용"})
+	if err != nil {
+		t.Fatalf("CreatePost: %v", err)
+	}
+	if created.ID != 101 || created.Title != "새 글" {
+		t.Errorf("CreatePost 결과가 다릅니다: %+v", created)
+	}
+
+	if _, err := c.UpdatePost(ctx, Post{Title: "id 없음"}); err == nil {
+		t.Error("UpdatePost 는 ID 가 0이면 오류를 반환해야 합니다")
+	}
+	updated, err := c.UpdatePost(ctx, Post{ID: 1, UserID: 1, Title: "수정"})
+	if err != nil {
+		t.Fatalf("UpdatePost: %v", err)
+	}
+	if updated.Title != "수정" {
+		t.Errorf("UpdatePost 결과가 다릅니다: %+v", updated)
+	}
+
+	if err := c.DeletePost(ctx, 1); err != nil {
+		t.Errorf("DeletePost: %v", err)
+	}
+	if err := c.DeletePost(ctx, 2); !isHTTPStatus(http.StatusNotFound)(err) {
+		t.Errorf("없는 게시물 삭제는 404 HTTPError 여야 합니다: %v", err)
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	const etag = `"v1"`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(postFixture))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, 5*time.Second).WithConditionalRequests(true)
+	ctx := context.Background()
+
+	first, err := c.GetPostContext(ctx, 1)
+	if err != nil {
+		t.Fatalf("첫 요청: %v", err)
+	}
+	second, err := c.GetPostContext(ctx, 1)
+	if !errors.Is(err, ErrNotModified) {
+		t.Fatalf("두 번째 요청은 ErrNotModified 여야 합니다: %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("304 응답 시 이전 값이 반환되어야 합니다: got %+v, want %+v", second, first)
+	}
+}
+
+// BenchmarkFetchAllPosts 는 5000개 게시물 배열을 스트리밍 디코딩하는 비용을 측정합니다.
+func BenchmarkFetchAllPosts(b *testing.B) {
+	const n = 5000
+	posts := make([]Post, n)
+	for i := range posts {
+		posts[i] = Post{
+			UserID: i%10 + 1,
+			ID:     i + 1,
+			Title:  fmt.Sprintf("게시물 제목 %d", i+1),
+			Body:   "quia et suscipit\nsuscipit recusandae consequuntur expedita et cum\nreprehenderit molestiae ut ut quas totam",
+		}
+	}
+	payload, err := json.Marshal(posts)
+	if err != nil {
+		b.Fatalf("fixture 마샬링 실패: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(payload)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(b, srv.URL, 10*time.Second)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(payload)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		got, err := c.GetAllPostsContext(ctx)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(got) != n {
+			b.Fatalf("게시물 수 %d, 기대값 %d", len(got), n)
+		}
+	}
+}